@@ -0,0 +1,54 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/tucnak/telebot"
+)
+
+// ownerID is the Telegram user ID of the bot's operator, who can override
+// the creator and admin restrictions in every chat
+var ownerID int
+
+// loadOwnerID reads OWNER_ID. Without it no user has owner rights.
+func loadOwnerID() {
+	value := os.Getenv("OWNER_ID")
+	if value == "" {
+		return
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid OWNER_ID %q, no owner is set", value)
+		return
+	}
+	ownerID = id
+}
+
+// isOwner reports whether user operates the bot
+func isOwner(user *telebot.User) bool {
+	return ownerID != 0 && user.ID == ownerID
+}
+
+// canManageGame reports whether user may run creator-only commands on a game
+// created by creator: the creator themselves, a chat admin or the bot owner.
+// It may call the Telegram API, so it must not be called with the chat locked.
+func canManageGame(bot *telebot.Bot, chat *telebot.Chat, user *telebot.User, creator string) bool {
+	return getPlayerID(user) == creator || isOwner(user) || isChatAdmin(bot, chat, user)
+}
+
+// isChatAdmin reports whether user administers chat. Private chats have no
+// admins other than the user themselves.
+func isChatAdmin(bot *telebot.Bot, chat *telebot.Chat, user *telebot.User) bool {
+	if chat.Type == telebot.ChatPrivate {
+		return true
+	}
+
+	member, err := bot.ChatMemberOf(chat, user)
+	if err != nil {
+		log.Printf("Error checking admin status of %d in chat %d: %v", user.ID, chat.ID, err)
+		return false
+	}
+	return member.Role == telebot.Creator || member.Role == telebot.Administrator
+}
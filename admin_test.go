@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/tucnak/telebot"
+)
+
+// roundTripFunc serves HTTP requests with a function
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// fakeBotAPI answers the bot's API calls with reply instead of Telegram, for
+// as long as the test runs. reply gets the method and its parameters.
+func fakeBotAPI(t *testing.T, reply func(method string, params map[string]string) string) *telebot.Bot {
+	t.Helper()
+	old := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		params := make(map[string]string)
+		if req.Body != nil {
+			json.NewDecoder(req.Body).Decode(&params)
+		}
+		body := reply(path.Base(req.URL.Path), params)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	})
+	t.Cleanup(func() { http.DefaultTransport = old })
+	return &telebot.Bot{Token: "test", Me: &telebot.User{ID: 1000, Username: "RouletteBot"}}
+}
+
+// memberStatuses makes getChatMember answer with the status of each user ID
+func memberStatuses(statuses map[string]string) func(string, map[string]string) string {
+	return func(method string, params map[string]string) string {
+		if method != "getChatMember" {
+			return `{"ok":false,"description":"unexpected method"}`
+		}
+		status, ok := statuses[params["user_id"]]
+		if !ok {
+			status = "member"
+		}
+		return `{"ok":true,"result":{"status":"` + status + `"}}`
+	}
+}
+
+func TestCanManageGame(t *testing.T) {
+	bot := fakeBotAPI(t, memberStatuses(map[string]string{"2": "administrator", "3": "creator"}))
+	oldOwner := ownerID
+	ownerID = 99
+	t.Cleanup(func() { ownerID = oldOwner })
+
+	group := &telebot.Chat{ID: -1001, Type: telebot.ChatGroup}
+	private := &telebot.Chat{ID: 5, Type: telebot.ChatPrivate}
+	tests := []struct {
+		name string
+		chat *telebot.Chat
+		user *telebot.User
+		want bool
+	}{
+		{"creator", group, &telebot.User{ID: 1, Username: "alice"}, true},
+		{"random member", group, &telebot.User{ID: 5, Username: "mallory"}, false},
+		{"chat admin", group, &telebot.User{ID: 2, Username: "bob"}, true},
+		{"chat owner", group, &telebot.User{ID: 3, Username: "carol"}, true},
+		{"bot operator", group, &telebot.User{ID: 99, Username: "operator"}, true},
+		{"private chat", private, &telebot.User{ID: 5, Username: "mallory"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canManageGame(bot, tt.chat, tt.user, "1"); got != tt.want {
+				t.Errorf("canManageGame(%s) = %t, want %t", tt.user.Username, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsChatAdminOnAPIError(t *testing.T) {
+	bot := fakeBotAPI(t, func(string, map[string]string) string {
+		return `{"ok":false,"description":"chat not found"}`
+	})
+	group := &telebot.Chat{ID: -1001, Type: telebot.ChatGroup}
+	if isChatAdmin(bot, group, &telebot.User{ID: 2}) {
+		t.Error("a failed member lookup made the user an admin")
+	}
+}
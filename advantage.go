@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// positionalOdds returns each seat's chance in percent of losing a game with
+// cfg and that many players, if every player pulls exactly once per turn and
+// nobody skips. It ignores the house edge, which moves bullets but doesn't
+// change how likely a pull is to be fatal.
+func positionalOdds(cfg GameConfig, players int) []float64 {
+	modes[cfg.Mode].apply(&cfg)
+	odds := make([]float64, players)
+
+	if cfg.SpinEach {
+		// Every pull has the same chance p, so seat i loses if the first i
+		// pulls of the round survive and its own doesn't, in whichever round
+		p := float64(cfg.Bullets) / float64(cfg.Chambers)
+		round := 1 - math.Pow(1-p, float64(players))
+		for i := range odds {
+			odds[i] = 100 * p * math.Pow(1-p, float64(i)) / round
+		}
+		return odds
+	}
+
+	// The first bullet is in the kth chamber after the safe ones with chance
+	// C(n-k, bullets-1) / C(n, bullets), and that pull falls to a fixed seat
+	n := cfg.Chambers - cfg.SafePulls
+	for k := 1; k <= n-cfg.Bullets+1; k++ {
+		seat := (cfg.SafePulls + k - 1) % players
+		odds[seat] += 100 * binomial(n-k, cfg.Bullets-1) / binomial(n, cfg.Bullets)
+	}
+	return odds
+}
+
+// binomial returns n choose k
+func binomial(n, k int) float64 {
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result *= float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+// renderAdvantage shows the chance of losing of each seat of the game
+func renderAdvantage(game *Game) string {
+	cfg := game.GameConfig
+	modes[cfg.Mode].apply(&cfg)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🎯 Chance of losing per seat with %d bullet(s) in %d chambers, if everyone pulls once per turn:", cfg.Bullets, cfg.Chambers)
+	for i, odds := range positionalOdds(game.GameConfig, len(game.Players)) {
+		fmt.Fprintf(&b, "\n%d. %s: %.1f%%", i+1, game.name(game.Players[i]), odds)
+	}
+	fmt.Fprintf(&b, "\nA fair game gives everyone %.1f%%.", 100/float64(len(game.Players)))
+	if game.Phase == PhaseLobby && !game.NoShuffle {
+		b.WriteString("\nSeats are in join order for now, the turn order is shuffled when the game starts.")
+	}
+	if game.Phase == PhaseLobby && game.FairStart {
+		b.WriteString("\nWith fairstart the first seat is only picked when the game starts.")
+	}
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPositionalOdds(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     func(cfg *GameConfig)
+		players int
+		want    []float64
+	}{
+		// One bullet is equally likely in each of the 6 chambers, two per seat
+		{"6 chambers, 3 players", func(cfg *GameConfig) {}, 3, []float64{100.0 / 3, 100.0 / 3, 100.0 / 3}},
+		// Seats 1 and 2 face chambers 1, 5 and 2, 6, seats 3 and 4 only 3 and 4
+		{"6 chambers, 4 players", func(cfg *GameConfig) {}, 4, []float64{100.0 / 3, 100.0 / 3, 100.0 / 6, 100.0 / 6}},
+		// The first of 2 bullets is in chamber k with chance (6-k)/15
+		{"2 bullets", func(cfg *GameConfig) { cfg.Bullets = 2 }, 3, []float64{100 * 7.0 / 15, 100 * 5.0 / 15, 100 * 3.0 / 15}},
+		// Chamber 1 is empty, the bullet is in one of the 5 others
+		{"a safe pull", func(cfg *GameConfig) { cfg.SafePulls = 1 }, 3, []float64{20, 40, 40}},
+		// Each pull is fatal with 1/6, so the first seat loses 6 in 11 games
+		{"spin each pull", func(cfg *GameConfig) { cfg.SpinEach = true }, 2, []float64{100 * 6.0 / 11, 100 * 5.0 / 11}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			tt.cfg(&cfg)
+			got := positionalOdds(cfg, tt.players)
+			if len(got) != len(tt.want) {
+				t.Fatalf("positionalOdds() = %v, want %v", got, tt.want)
+			}
+			total := 0.0
+			for seat := range got {
+				total += got[seat]
+				if math.Abs(got[seat]-tt.want[seat]) > 1e-9 {
+					t.Errorf("seat %d loses with %.4f%%, want %.4f%%", seat+1, got[seat], tt.want[seat])
+				}
+			}
+			if math.Abs(total-100) > 1e-9 {
+				t.Errorf("odds add up to %.4f%%, want 100%%", total)
+			}
+		})
+	}
+}
+
+func TestRenderAdvantage(t *testing.T) {
+	game := lobbyGame(t, defaultConfig(), lastInChamber())
+	got := renderAdvantage(game)
+	for _, want := range []string{
+		"with 1 bullet(s) in 6 chambers",
+		"1. Alice: 33.3%",
+		"3. carol: 33.3%",
+		"A fair game gives everyone 33.3%.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report lacks %q:\n%s", want, got)
+		}
+	}
+}
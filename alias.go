@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tucnak/telebot"
+)
+
+// maxAliases caps how many aliases a chat can define
+const maxAliases = 20
+
+var aliasPattern = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// commands are the handlers of every command by name, without the slash, so an
+// alias can run the command it stands for. It is filled before the bot starts
+// and only read afterwards.
+var commands = make(map[string]func(*telebot.Message))
+
+// runAlias handles a command telebot has no handler for. If the chat defined it
+// as an alias the handler of its command runs instead, with the same payload.
+func runAlias(m *telebot.Message) {
+	if !strings.HasPrefix(m.Text, "/") {
+		return
+	}
+	command := strings.Fields(m.Text)[0][1:]
+	command, _, _ = strings.Cut(command, "@")
+
+	target, ok := chats.get(m.Chat.ID).Aliases[strings.ToLower(command)]
+	if !ok {
+		return
+	}
+	if handler, ok := commands[target]; ok {
+		handler(m)
+	}
+}
+
+// addAlias makes alias run the target command in the chat and saves the store.
+// Targets must be real commands, which aliases never are, so aliases can't loop.
+func (s *chatStore) addAlias(chatID int64, alias, target string) error {
+	alias = strings.ToLower(strings.TrimPrefix(alias, "/"))
+	target = strings.ToLower(strings.TrimPrefix(target, "/"))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cfg := s.lookup(chatID)
+
+	if !aliasPattern.MatchString(alias) {
+		return fmt.Errorf("%w: an alias is up to 32 letters, digits or underscores", ErrInvalidOption)
+	}
+	if _, exists := commands[alias]; exists {
+		return fmt.Errorf("%w: /%s is already a command", ErrInvalidOption, alias)
+	}
+	if _, exists := cfg.Aliases[target]; exists {
+		return fmt.Errorf("%w: /%s is an alias itself, point at the command it stands for", ErrInvalidOption, target)
+	}
+	if _, exists := commands[target]; !exists {
+		return fmt.Errorf("%w: there is no /%s command", ErrInvalidOption, target)
+	}
+	if _, exists := cfg.Aliases[alias]; !exists && len(cfg.Aliases) >= maxAliases {
+		return fmt.Errorf("%w: a chat can have at most %d aliases", ErrInvalidOption, maxAliases)
+	}
+
+	aliases := make(map[string]string, len(cfg.Aliases)+1)
+	for a, t := range cfg.Aliases {
+		aliases[a] = t
+	}
+	aliases[alias] = target
+	cfg.Aliases = aliases
+	s.Chats[chatID] = &cfg
+	return s.save()
+}
+
+// removeAlias deletes an alias of the chat and saves the store
+func (s *chatStore) removeAlias(chatID int64, alias string) error {
+	alias = strings.ToLower(strings.TrimPrefix(alias, "/"))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cfg := s.lookup(chatID)
+	if _, exists := cfg.Aliases[alias]; !exists {
+		return fmt.Errorf("%w: there is no /%s alias", ErrInvalidOption, alias)
+	}
+
+	aliases := make(map[string]string, len(cfg.Aliases))
+	for a, t := range cfg.Aliases {
+		if a != alias {
+			aliases[a] = t
+		}
+	}
+	cfg.Aliases = aliases
+	s.Chats[chatID] = &cfg
+	return s.save()
+}
+
+// renderAliases lists the aliases of a chat
+func renderAliases(aliases map[string]string) string {
+	if len(aliases) == 0 {
+		return "This chat has no aliases. Admins can add one with /alias add <alias> <command>."
+	}
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("🔀 Aliases in this chat")
+	for _, alias := range names {
+		fmt.Fprintf(&b, "\n/%s → /%s", alias, aliases[alias])
+	}
+	return b.String()
+}
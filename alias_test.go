@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tucnak/telebot"
+)
+
+// testCommands stands in for the bot's commands, recording which ran
+func testCommands(t *testing.T) *[]string {
+	t.Helper()
+	var ran []string
+	old := commands
+	commands = make(map[string]func(*telebot.Message))
+	for _, name := range []string{"pull", "skip", "stats"} {
+		commands[name] = func(m *telebot.Message) { ran = append(ran, name+" "+m.Payload) }
+	}
+	t.Cleanup(func() { commands = old })
+	return &ran
+}
+
+func TestAddAlias(t *testing.T) {
+	chat := testChat(t)
+	testCommands(t)
+	if err := chats.addAlias(chat.ID, "shoot", "pull"); err != nil {
+		t.Fatalf("addAlias(shoot, pull) = %v", err)
+	}
+
+	tests := []struct {
+		alias, target string
+		wantErr       string
+	}{
+		{"/Bang", "/pull", ""},
+		{"shoot", "skip", ""},
+		{"fire", "shoot", "is an alias itself"},
+		{"fire", "explode", "there is no /explode command"},
+		{"pull", "skip", "/pull is already a command"},
+		{"no-dashes", "pull", "up to 32 letters"},
+		{"", "pull", "up to 32 letters"},
+	}
+	for _, tt := range tests {
+		err := chats.addAlias(chat.ID, tt.alias, tt.target)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("addAlias(%q, %q) = %v", tt.alias, tt.target, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrInvalidOption) || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("addAlias(%q, %q) = %v, want an error saying %q", tt.alias, tt.target, err, tt.wantErr)
+		}
+	}
+
+	want := map[string]string{"bang": "pull", "shoot": "skip"}
+	if got := chats.get(chat.ID).Aliases; len(got) != len(want) || got["bang"] != "pull" || got["shoot"] != "skip" {
+		t.Errorf("aliases = %v, want %v", got, want)
+	}
+}
+
+func TestRunAlias(t *testing.T) {
+	chat := testChat(t)
+	ran := testCommands(t)
+	if err := chats.addAlias(chat.ID, "shoot", "pull"); err != nil {
+		t.Fatalf("addAlias(shoot, pull) = %v", err)
+	}
+	other := &telebot.Chat{ID: chat.ID - 1, Type: telebot.ChatGroup}
+
+	tests := []struct {
+		name string
+		chat *telebot.Chat
+		text string
+		want string
+	}{
+		{"alias", chat, "/shoot", "pull "},
+		{"alias with a suffix and payload", chat, "/SHOOT@RouletteBot confirm", "pull confirm"},
+		{"unknown command", chat, "/fire", ""},
+		{"alias of another chat", other, "/shoot", ""},
+		{"not a command", chat, "shoot", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*ran = nil
+			_, payload, _ := strings.Cut(tt.text, " ")
+			runAlias(&telebot.Message{ID: 1, Chat: tt.chat, Text: tt.text, Payload: payload})
+			if got := strings.Join(*ran, ","); got != tt.want {
+				t.Errorf("%q ran %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	if err := chats.removeAlias(chat.ID, "/shoot"); err != nil {
+		t.Fatalf("removeAlias(shoot) = %v", err)
+	}
+	*ran = nil
+	runAlias(&telebot.Message{ID: 2, Chat: chat, Text: "/shoot"})
+	if len(*ran) != 0 {
+		t.Errorf("a removed alias ran %q", *ran)
+	}
+	if err := chats.removeAlias(chat.ID, "shoot"); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("removing a missing alias = %v, want %v", err, ErrInvalidOption)
+	}
+}
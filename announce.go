@@ -0,0 +1,86 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+// endAttempts is how often the announcement that ends a game is tried before
+// it is left for the chat's next command
+const endAttempts = 3
+
+// endRetryDelay is the pause before the first retry, doubling on each further one
+const endRetryDelay = 500 * time.Millisecond
+
+// announceEnd sends the message that ends a game. If that fails it is kept as
+// the room's unannounced message and sent ahead of the chat's next command, so
+// a game never disappears without a word, and meanwhile retried a few times in
+// the background. The caller has the chat locked, which the retries don't, so
+// a flaky send doesn't stall the chat.
+func announceEnd(s Sender, chat *telebot.Chat, text string) {
+	_, err := s.Send(chat, text)
+	if err == nil {
+		return
+	}
+	log.Printf("Error announcing the end of the game in chat %d (attempt 1): %v", chat.ID, err)
+	roomOf(chat.ID).unannounced = text
+	go retryAnnounceEnd(s, chat, text)
+}
+
+// retryAnnounceEnd keeps trying to deliver a failed end of game announcement
+// until it goes out, runs out of attempts or a command delivered it first. Each
+// attempt takes the text off the queue while sending, so it never goes out twice.
+func retryAnnounceEnd(s Sender, chat *telebot.Chat, text string) {
+	delay := endRetryDelay
+	for attempt := 2; attempt <= endAttempts; attempt++ {
+		time.Sleep(delay)
+		delay *= 2
+
+		r := lockChat(chat.ID)
+		if r.unannounced != text {
+			r.unlock()
+			return
+		}
+		r.unannounced = ""
+		r.unlock()
+
+		_, err := s.Send(chat, text)
+		if err == nil {
+			return
+		}
+		log.Printf("Error announcing the end of the game in chat %d (attempt %d): %v", chat.ID, attempt, err)
+
+		r = lockChat(chat.ID)
+		if r.unannounced == "" {
+			r.unannounced = text
+		}
+		r.unlock()
+	}
+}
+
+// deliverUnannounced sends the chat's pending end of game announcement, if any.
+// The caller has the chat locked.
+func deliverUnannounced(s Sender, chat *telebot.Chat) {
+	r := roomOf(chat.ID)
+	if r.unannounced == "" {
+		return
+	}
+	if _, err := s.Send(chat, r.unannounced); err != nil {
+		log.Printf("Error delivering the end of the game in chat %d: %v", chat.ID, err)
+		return
+	}
+	r.unannounced = ""
+}
+
+// withUnannounced makes a handler deliver the chat's pending end of game
+// announcement before it replies to anything else
+func withUnannounced(s Sender, handler func(*telebot.Message)) func(*telebot.Message) {
+	return func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		deliverUnannounced(s, m.Chat)
+		r.unlock()
+		handler(m)
+	}
+}
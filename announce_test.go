@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+func TestFailedEndIsAnnouncedLater(t *testing.T) {
+	tests := []struct {
+		name    string
+		deliver func(s *recordingSender, chat *telebot.Chat)
+	}{
+		{"by the next command", func(s *recordingSender, chat *telebot.Chat) {
+			handler := withUnannounced(s, func(m *telebot.Message) { s.Send(m.Chat, "reply") })
+			handler(&telebot.Message{ID: 2, Chat: chat, Sender: &telebot.User{ID: 2, Username: "bob"}, Text: "/stats"})
+		}},
+		{"by a retry", func(s *recordingSender, chat *telebot.Chat) {
+			time.Sleep(endRetryDelay + 200*time.Millisecond)
+		}},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Each case plays in its own chat, as the retries of an earlier
+			// case may still be waiting to look at theirs
+			chat := testChat(t)
+			chat.ID -= int64(i)
+			game := playChat(t, chat, defaultConfig(), &scriptedRandomizer{})
+			s := &recordingSender{sendErr: errors.New("telegram is down")}
+
+			// The bullet is in the first chamber, so alice ends the game
+			r := lockChat(chat.ID)
+			playPull(s, chat, game, "alice")
+			text := r.unannounced
+			r.unlock()
+			if text == "" {
+				t.Fatal("the failed end of the game wasn't kept for later")
+			}
+
+			s.mu.Lock()
+			s.sendErr = nil
+			s.mu.Unlock()
+			tt.deliver(s, chat)
+
+			if got := s.sentWith(text); len(got) != 1 {
+				t.Errorf("end of the game sent %d time(s), want once: %q", len(got), s.sent)
+			}
+			r = lockChat(chat.ID)
+			defer r.unlock()
+			if r.unannounced != "" {
+				t.Errorf("the end of the game is still pending: %q", r.unannounced)
+			}
+			if tt.name == "by the next command" && (len(s.sent) != 2 || s.sent[1] != "reply") {
+				t.Errorf("sent %q, want the end of the game ahead of the reply", s.sent)
+			}
+		})
+	}
+}
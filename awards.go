@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pullTally is what one player did with the trigger during a game
+type pullTally struct {
+	Survived int
+	Luck     float64 // Sum of the fatal odds of every survived pull
+}
+
+// tallyPulls counts each player's survived pulls from the game's event log
+func tallyPulls(g *Game) map[string]pullTally {
+	tallies := make(map[string]pullTally)
+	for _, e := range g.Events {
+		if e.Kind != EventPull {
+			continue
+		}
+		t := tallies[e.Player]
+		t.Survived++
+		t.Luck += e.Odds
+		tallies[e.Player] = t
+	}
+	return tallies
+}
+
+// mvps returns the players who survived the most pulls, with the odds they
+// survived breaking ties. Several players are returned if they are still level.
+func mvps(g *Game) []string {
+	tallies := tallyPulls(g)
+
+	var best []string
+	var bestTally pullTally
+	for _, player := range g.roster() {
+		t, ok := tallies[player]
+		if !ok {
+			continue
+		}
+		switch {
+		case len(best) == 0 || t.Survived > bestTally.Survived ||
+			(t.Survived == bestTally.Survived && t.Luck > bestTally.Luck):
+			best, bestTally = []string{player}, t
+		case t.Survived == bestTally.Survived && t.Luck == bestTally.Luck:
+			best = append(best, player)
+		}
+	}
+	return best
+}
+
+// firstToDie returns the first player killed in the game, if any
+func firstToDie(g *Game) (string, bool) {
+	for _, e := range g.Events {
+		if e.Kind == EventDeath {
+			return e.Player, true
+		}
+	}
+	return "", false
+}
+
+// gameAwards announces the MVP and the wooden spoon of a finished game.
+// It is empty when nobody survived a pull, since there is nothing to celebrate.
+func gameAwards(g *Game) string {
+	best := mvps(g)
+	if len(best) == 0 {
+		return ""
+	}
+
+	t := tallyPulls(g)[best[0]]
+	var b strings.Builder
+	fmt.Fprintf(&b, "🏆 MVP: %s with %d survived pull(s)", g.nameList(best), t.Survived)
+	if len(best) > 1 {
+		b.WriteString(" each")
+	}
+
+	if dead, ok := firstToDie(g); ok {
+		fmt.Fprintf(&b, "\n🥄 Wooden spoon: %s, first to die", g.name(dead))
+	}
+	return b.String()
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+// botTurnDelay is how long a bot player "thinks" before each move
+const botTurnDelay = 2 * time.Second
+
+// defaultBotThreshold is the fatal odds at which the odds strategy stops pulling
+const defaultBotThreshold = 30.0
+
+const botStrategyHelp = `
+once - always pull exactly once, then pass
+odds <percent> - keep pulling until the next pull is at least that likely to be fatal
+random - sometimes skips, otherwise pulls once`
+
+type botAction int
+
+const (
+	botPull botAction = iota
+	botPass
+	botSkip
+)
+
+// BotPlayer is a computer controlled player and the strategy it plays with
+type BotPlayer struct {
+	Strategy  string
+	Threshold float64 // Fatal odds in percent at which the odds strategy passes
+}
+
+func (bp BotPlayer) String() string {
+	if bp.Strategy == "odds" {
+		return fmt.Sprintf("%s %.0f%%", bp.Strategy, bp.Threshold)
+	}
+	return bp.Strategy
+}
+
+// parseBotPlayer reads the /addbot payload, defaulting to the once strategy
+func parseBotPlayer(payload string) (BotPlayer, error) {
+	fields := strings.Fields(strings.ToLower(payload))
+	if len(fields) == 0 {
+		return BotPlayer{Strategy: "once"}, nil
+	}
+
+	switch fields[0] {
+	case "once", "random":
+		return BotPlayer{Strategy: fields[0]}, nil
+	case "odds":
+		bp := BotPlayer{Strategy: "odds", Threshold: defaultBotThreshold}
+		if len(fields) > 1 {
+			threshold, err := strconv.ParseFloat(strings.TrimSuffix(fields[1], "%"), 64)
+			if err != nil || threshold <= 0 || threshold > 100 {
+				return BotPlayer{}, fmt.Errorf("%w: bad odds threshold %q", ErrUnknownStrategy, fields[1])
+			}
+			bp.Threshold = threshold
+		}
+		return bp, nil
+	}
+	return BotPlayer{}, fmt.Errorf("%w: %s", ErrUnknownStrategy, fields[0])
+}
+
+// decide picks the bot's next move for the current state of the game
+func (bp BotPlayer) decide(g *Game, player string) botAction {
+	if g.HasPulledOnTurn {
+		if bp.Strategy == "odds" && g.NextOdds() < bp.Threshold {
+			return botPull
+		}
+		return botPass
+	}
+
+	if bp.Strategy == "random" && g.Skips[player] > 0 && g.rng.Intn(3) == 0 {
+		return botSkip
+	}
+	return botPull
+}
+
+// AddBot seats a new bot player in the lobby and returns its player ID
+func (g *Game) AddBot(bp BotPlayer) (string, error) {
+	id := fmt.Sprintf("🤖bot%d", len(g.Bots)+1)
+	if err := g.Join(id, id); err != nil {
+		return "", err
+	}
+
+	g.Bots[id] = bp
+	return id, nil
+}
+
+// scheduleBot makes the current player move after a delay if it is a bot.
+// The timer re-checks the game with the chat locked in case it was replaced meanwhile.
+func scheduleBot(s Sender, chat *telebot.Chat, game *Game) {
+	if !game.IsActive || game.Phase != PhaseRunning {
+		return
+	}
+	player := game.CurrentPlayer()
+	bp, ok := game.Bots[player]
+	if !ok {
+		return
+	}
+
+	generation := game.Generation
+	time.AfterFunc(botTurnDelay, func() {
+		defer lockChat(chat.ID).unlock()
+
+		game, err := liveGame(chat.ID, generation)
+		if err != nil || game.Phase != PhaseRunning || game.CurrentPlayer() != player {
+			return
+		}
+
+		switch bp.decide(game, player) {
+		case botPull:
+			playPull(s, chat, game, player)
+		case botPass:
+			playPass(s, chat, game, player)
+		case botSkip:
+			playSkip(s, chat, game, player)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/tucnak/telebot"
+)
+
+// turnButtons are the moves offered under turn announcements, so players on
+// mobile don't have to type /pull, /pass and /skip
+var turnButtons = []telebot.InlineButton{
+	{Unique: "pull", Text: "🔫 Pull"},
+	{Unique: "pass", Text: "➡️ Pass"},
+	{Unique: "skip", Text: "⏭ Skip"},
+}
+
+// turnOptions returns the send options that attach the turn buttons to an
+// announcement of the game's current turn. The buttons carry the game's
+// generation and turn, so pressing one under the announcement of an earlier
+// turn does nothing. A bot's turn gets no buttons, since no one can press them
+// for it.
+func turnOptions(game *Game) []interface{} {
+	if _, isBot := game.Bots[game.CurrentPlayer()]; isBot || game.Phase != PhaseRunning {
+		return nil
+	}
+	row := make([]telebot.InlineButton, len(turnButtons))
+	for i, button := range turnButtons {
+		button.Data = strconv.FormatUint(game.Generation, 36) + "." + strconv.Itoa(game.Turn)
+		row[i] = button
+	}
+	return []interface{}{&telebot.ReplyMarkup{InlineKeyboard: [][]telebot.InlineButton{row}}}
+}
+
+// pressTurnButton handles a press of the turn button for action. The presser
+// is held to the same rules as the commands, and whatever stops a move is
+// shown to them alone instead of the whole chat.
+func pressTurnButton(bot *telebot.Bot, s Sender, action string) func(*telebot.Callback) {
+	respond := func(c *telebot.Callback, text string) {
+		if err := bot.Respond(c, &telebot.CallbackResponse{Text: text}); err != nil {
+			log.Printf("Error answering button press: %v", err)
+		}
+	}
+
+	return func(c *telebot.Callback) {
+		chat := c.Message.Chat
+
+		r := lockChat(chat.ID)
+		defer r.unlock()
+
+		if r.handled.seenPress(c.ID) {
+			return
+		}
+
+		generationData, turnData, _ := strings.Cut(c.Data, ".")
+		generation, err := strconv.ParseUint(generationData, 36, 64)
+		if err != nil {
+			respond(c, errorMessage(ErrStaleGame, nil))
+			return
+		}
+		game, err := liveGame(chat.ID, generation)
+		if err != nil {
+			respond(c, errorMessage(err, nil))
+			return
+		}
+		if turn, err := strconv.Atoi(turnData); err != nil || turn != game.Turn {
+			respond(c, errorMessage(ErrStaleTurn, game))
+			return
+		}
+		player := getPlayerID(c.Sender)
+		if err := game.requireTurn(player); err != nil {
+			respond(c, errorMessage(err, game))
+			return
+		}
+
+		switch action {
+		case "pull":
+			// A second press confirms a pull the player was warned about
+			if err := game.ConfirmPull(player, game.confirming); err != nil {
+				respond(c, "")
+				senderFor(s, game).Send(chat, errorMessage(err, game)+"\nOr press Pull again.", turnOptions(game)...)
+				return
+			}
+			respond(c, "")
+			playPull(s, chat, game, player)
+		case "pass":
+			respond(c, "")
+			playPass(s, chat, game, player)
+		case "skip":
+			respond(c, "")
+			game.peeker = c.Sender
+			playSkip(s, chat, game, player)
+		}
+	}
+}
+
+// wrapPress runs a button press handler behind wrap, the wrappers every command
+// runs behind, which see the press as a message from the presser in its chat.
+// The command guard is left out, as a press is always addressed to this bot.
+func wrapPress(wrap func(func(*telebot.Message)) func(*telebot.Message), handler func(*telebot.Callback)) func(*telebot.Callback) {
+	return func(c *telebot.Callback) {
+		if c.Message == nil || c.Sender == nil {
+			return
+		}
+		m := &telebot.Message{Sender: c.Sender, Chat: c.Message.Chat}
+		wrap(func(*telebot.Message) { handler(c) })(m)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/tucnak/telebot"
+)
+
+func TestTurnButtonsOfAnEarlierTurn(t *testing.T) {
+	var answers []string
+	bot := fakeBotAPI(t, func(method string, params map[string]string) string {
+		if method == "answerCallbackQuery" {
+			answers = append(answers, params["text"])
+		}
+		return `{"ok":true,"result":true}`
+	})
+	chat := testChat(t)
+	cfg := defaultConfig()
+	cfg.NoShuffle = true
+	game := newGame("1", "@alice", cfg, lastInChamber())
+	game.Join("2", "@bob")
+	game.Start()
+	game.Generation = nextGeneration()
+	roomOf(chat.ID).game = game
+	s := &recordingSender{}
+	alice, bob := &telebot.User{ID: 1, Username: "alice"}, &telebot.User{ID: 2, Username: "bob"}
+	buttonData := func() string {
+		markup := turnOptions(game)[0].(*telebot.ReplyMarkup)
+		return markup.InlineKeyboard[0][0].Data
+	}
+	skip := pressTurnButton(bot, s, "skip")
+
+	// alice's buttons, which bob's turn will make old
+	old := buttonData()
+	skip(&telebot.Callback{ID: "1", Sender: alice, Message: &telebot.Message{Chat: chat}, Data: old})
+	if game.CurrentPlayer() != "2" {
+		t.Fatalf("%s is up after alice's skip, want bob", game.CurrentPlayer())
+	}
+	skip(&telebot.Callback{ID: "2", Sender: alice, Message: &telebot.Message{Chat: chat}, Data: buttonData()})
+	skip(&telebot.Callback{ID: "3", Sender: bob, Message: &telebot.Message{Chat: chat}, Data: old})
+	if game.CurrentPlayer() != "2" || game.Skips["2"] != defaultSkips {
+		t.Fatalf("bob skipped with an old button: %s is up, bob has %d skip(s)", game.CurrentPlayer(), game.Skips["2"])
+	}
+	want := []string{"", "It's not your turn! Waiting for @bob to play.", "Those buttons are from an earlier turn!"}
+	if len(answers) != len(want) {
+		t.Fatalf("answers = %q, want %q", answers, want)
+	}
+	for i := range want {
+		if answers[i] != want[i] {
+			t.Errorf("answer %d = %q, want %q", i, answers[i], want[i])
+		}
+	}
+
+	// The buttons of bob's own turn still work
+	skip(&telebot.Callback{ID: "4", Sender: bob, Message: &telebot.Message{Chat: chat}, Data: buttonData()})
+	if game.CurrentPlayer() != "1" || game.Skips["2"] != defaultSkips-1 {
+		t.Errorf("bob's skip wasn't played: %s is up, bob has %d skip(s)", game.CurrentPlayer(), game.Skips["2"])
+	}
+}
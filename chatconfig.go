@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultChatsFile = "data/chats.json"
+
+// ChatConfig is the per-chat configuration changed with /config
+type ChatConfig struct {
+	Defaults  GameConfig // Settings new games in the chat start with
+	Timezone  string     `json:",omitempty"` // IANA zone timestamps are shown in, UTC when empty
+	Reactions string     // Whether pulls are answered with emoji reactions, see reactionsOn
+	Verbosity string     // Which messages the game can do without are sent, see verbosityMinimal
+	// Commands in the group must be addressed to the bot, as in /pull@MyBot,
+	// so they can't collide with other bots' commands
+	RequireSuffix bool
+	Aliases       map[string]string `json:",omitempty"` // Commands by the alias that runs them, without slashes
+}
+
+// location returns the chat's timezone. A zone that can no longer be loaded falls back to UTC.
+func (c ChatConfig) location() *time.Location {
+	if c.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func defaultChatConfig() ChatConfig {
+	return ChatConfig{Defaults: defaultConfig(), Reactions: reactionsOff, Verbosity: verbosityNormal}
+}
+
+// MarshalJSON saves only the game settings the chat changed from the defaults,
+// under Changed, so the others follow the bot's defaults even when they change
+func (c ChatConfig) MarshalJSON() ([]byte, error) {
+	type plain ChatConfig
+	changed, err := changedSettings(c.Defaults)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		plain
+		Defaults json.RawMessage            `json:",omitempty"`
+		Changed  map[string]json.RawMessage `json:",omitempty"`
+	}{plain: plain(c), Changed: changed})
+}
+
+// UnmarshalJSON starts from the defaults so settings added since the file was
+// written get their default value instead of zero. Files written before only
+// the changed settings were saved hold every setting under Defaults, where a
+// zero turn timer or player cap just meant the default of the time.
+func (c *ChatConfig) UnmarshalJSON(data []byte) error {
+	type plain ChatConfig
+	f := struct {
+		plain
+		Defaults json.RawMessage
+		Changed  json.RawMessage
+	}{plain: plain(defaultChatConfig())}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	switch {
+	case f.Changed != nil:
+		if err := json.Unmarshal(f.Changed, &f.plain.Defaults); err != nil {
+			return err
+		}
+	case f.Defaults != nil:
+		if err := json.Unmarshal(f.Defaults, &f.plain.Defaults); err != nil {
+			return err
+		}
+		if f.plain.Defaults.TurnTimeout == 0 {
+			f.plain.Defaults.TurnTimeout = defaultTurnTimeout
+		}
+		if f.plain.Defaults.MaxPlayers == 0 {
+			f.plain.Defaults.MaxPlayers = defaultMaxPlayers
+		}
+	}
+	*c = ChatConfig(f.plain)
+	return nil
+}
+
+// changedSettings returns the settings of cfg that differ from the defaults,
+// as the JSON fields they are saved as
+func changedSettings(cfg GameConfig) (map[string]json.RawMessage, error) {
+	settings, err := settingFields(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := settingFields(defaultConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string]json.RawMessage)
+	for field, value := range settings {
+		if string(value) != string(defaults[field]) {
+			changed[field] = value
+		}
+	}
+	return changed, nil
+}
+
+// settingFields splits cfg into its JSON fields
+func settingFields(cfg GameConfig) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	return fields, json.Unmarshal(data, &fields)
+}
+
+// chatStore keeps the configuration of every chat and persists it as JSON.
+// It is shared by every chat, so its methods lock it themselves.
+type chatStore struct {
+	mu    sync.Mutex
+	path  string
+	Chats map[int64]*ChatConfig
+}
+
+// loadChatConfigs reads the chat config file at path. A missing file yields an empty store.
+func loadChatConfigs(path string) (*chatStore, error) {
+	s := &chatStore{path: path, Chats: make(map[int64]*ChatConfig)}
+	return s, readJSONFile(path, &s.Chats)
+}
+
+// save writes the store to disk. The caller holds s.mu.
+func (s *chatStore) save() error {
+	return writeJSONFile(s.path, s.Chats)
+}
+
+// get returns a copy of the chat's configuration, the defaults if never configured.
+// The copy's aliases are shared, so they are replaced rather than changed.
+func (s *chatStore) get(chatID int64) ChatConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lookup(chatID)
+}
+
+// lookup is get for callers that hold s.mu
+func (s *chatStore) lookup(chatID int64) ChatConfig {
+	if cfg, ok := s.Chats[chatID]; ok {
+		return *cfg
+	}
+	return defaultChatConfig()
+}
+
+// set changes one setting of the chat's configuration and saves the store
+func (s *chatStore) set(chatID int64, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cfg := s.lookup(chatID)
+	if key == "tz" {
+		// Zone names are case sensitive, e.g. Europe/London
+		if _, err := time.LoadLocation(value); err != nil || value == "Local" {
+			return fmt.Errorf("%w: unknown timezone %q, use a name like Europe/London", ErrInvalidOption, value)
+		}
+		cfg.Timezone = value
+	} else if key == "suffix" {
+		switch strings.ToLower(value) {
+		case "on", "yes", "true":
+			cfg.RequireSuffix = true
+		case "off", "no", "false":
+			cfg.RequireSuffix = false
+		default:
+			return fmt.Errorf("%w: suffix must be on or off", ErrInvalidOption)
+		}
+	} else if key == "reactions" {
+		switch value = strings.ToLower(value); value {
+		case reactionsOff, reactionsOn, reactionsOnly:
+			cfg.Reactions = value
+		default:
+			return fmt.Errorf("%w: reactions must be on, off or only", ErrInvalidOption)
+		}
+	} else if key == "verbosity" {
+		value = strings.ToLower(value)
+		if _, ok := verbosityRanks[value]; !ok {
+			return fmt.Errorf("%w: verbosity must be minimal, normal or verbose", ErrInvalidOption)
+		}
+		cfg.Verbosity = value
+	} else if err := setOption(key, strings.ToLower(value), &cfg.Defaults); err != nil {
+		return err
+	}
+
+	s.Chats[chatID] = &cfg
+	return s.save()
+}
+
+// renderRules describes the rules a game created with cfg is played by
+func renderRules(cfg GameConfig) string {
+	var b strings.Builder
+	b.WriteString("📜 Rules for new games in this chat\n")
+	fmt.Fprintf(&b, "• Mode: %s - %s\n", cfg.Mode, modes[cfg.Mode].Description)
+
+	modes[cfg.Mode].apply(&cfg)
+	fmt.Fprintf(&b, "• The revolver has %d chambers and %d bullet(s).\n", cfg.Chambers, cfg.Bullets)
+	if cfg.SafePulls > 0 {
+		fmt.Fprintf(&b, "• The first %d pull(s) of each cylinder are always safe.\n", cfg.SafePulls)
+	}
+	b.WriteString("• On your turn pull as often as you dare, then /pass. Whoever hits the bullet loses.\n")
+	fmt.Fprintf(&b, "• Each player has %d skip(s) to /skip a turn without pulling.\n", cfg.SkipsPerPlayer)
+	if cfg.MaxPlayers > 0 {
+		fmt.Fprintf(&b, "• Up to %d players can join a game.\n", cfg.MaxPlayers)
+	}
+	if cfg.JamChance > 0 {
+		refund := "and the skip is used up"
+		if cfg.JamRefund {
+			refund = "but the skip isn't used up"
+		}
+		fmt.Fprintf(&b, "• A skip has a %d%% chance to jam, forcing a pull, %s.\n", cfg.JamChance, refund)
+	}
+	if cfg.JoinWindow > 0 {
+		fmt.Fprintf(&b, "• Games start by themselves %d seconds after they are created, if enough players joined.\n", cfg.JoinWindow)
+	}
+	if cfg.TurnTimeout > 0 {
+		fmt.Fprintf(&b, "• Each turn has a %d second limit. Use /more once per turn for extra time, otherwise you pass, skip or pull when it runs out.\n", cfg.TurnTimeout)
+	}
+	if cfg.ClutchOdds > 0 {
+		fmt.Fprintf(&b, "• Surviving a pull with at least a %d%% chance of being fatal earns clutch points.\n", cfg.ClutchOdds)
+	}
+	if cfg.ConfirmOdds > 0 {
+		fmt.Fprintf(&b, "• A pull with at least a %d%% chance of being fatal has to be confirmed with /pull confirm.\n", cfg.ConfirmOdds)
+	}
+	if cfg.HouseEdge > 0 {
+		fmt.Fprintf(&b, "• The house cheats: before each pull there is a %d%% chance the bullets are secretly moved. It's revealed when the game ends.\n", cfg.HouseEdge)
+	}
+	if cfg.Reveal {
+		b.WriteString("• After each survived pull the bot says which chamber was empty.\n")
+	}
+	if cfg.Practice {
+		b.WriteString("• Games are practice: a death just reloads the cylinder and nothing counts towards the stats.\n")
+	}
+	if cfg.Host {
+		b.WriteString("• Whoever creates a game hosts it without playing.\n")
+	}
+	if cfg.NoShuffle {
+		b.WriteString("• Players take their turns in the order they joined.\n")
+	} else {
+		b.WriteString("• The turn order is shuffled when the game starts.\n")
+	}
+	if cfg.Elimination {
+		b.WriteString("• Elimination: a death only takes the player out and the cylinder is reloaded, until one survivor wins.\n")
+	}
+	if cfg.PeekSkip {
+		b.WriteString("• Using a skip lets you peek: the bot privately tells you whether the next chamber is loaded.\n")
+	}
+	if cfg.LateJoin {
+		b.WriteString("• Players can still join a running game and take the last seat of the round.\n")
+	}
+	if cfg.FairStart {
+		b.WriteString("• Players who have been unlucky here are more likely to go first.\n")
+	}
+	b.WriteString("Options given to /create override these for a single game.")
+	return b.String()
+}
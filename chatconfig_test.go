@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRulesFollowChatConfig(t *testing.T) {
+	tests := []struct {
+		key, value string
+		before     string
+		after      string
+	}{
+		{"chambers", "8", "6 chambers", "8 chambers"},
+		{"skips", "0", "2 skip(s)", "0 skip(s)"},
+		{"timer", "30", "120 second limit", "30 second limit"},
+		{"mode", "hardcore", "Mode: classic", "Mode: hardcore"},
+		{"reveal", "on", "", "which chamber was empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			chat := testChat(t)
+
+			rules := renderRules(chats.get(chat.ID).Defaults)
+			if tt.before != "" && !strings.Contains(rules, tt.before) {
+				t.Errorf("default rules lack %q:\n%s", tt.before, rules)
+			}
+			if strings.Contains(rules, tt.after) {
+				t.Errorf("default rules already have %q:\n%s", tt.after, rules)
+			}
+
+			if err := chats.set(chat.ID, tt.key, tt.value); err != nil {
+				t.Fatalf("set(%s, %s) = %v", tt.key, tt.value, err)
+			}
+			rules = renderRules(chats.get(chat.ID).Defaults)
+			if !strings.Contains(rules, tt.after) {
+				t.Errorf("rules after /config %s %s lack %q:\n%s", tt.key, tt.value, tt.after, rules)
+			}
+		})
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	chat := testChat(t)
+	at := time.Date(2024, time.July, 1, 12, 30, 0, 0, time.UTC)
+
+	if got := formatTime(at, chats.get(chat.ID).location()); got != "Mon 1 Jul 12:30 UTC" {
+		t.Errorf("timestamp without a timezone = %q, want it in UTC", got)
+	}
+
+	tests := []struct {
+		zone    string
+		want    string
+		wantErr bool
+	}{
+		{"Europe/London", "Mon 1 Jul 13:30 BST", false},
+		{"Asia/Tokyo", "Mon 1 Jul 21:30 JST", false},
+		{"Mars/Olympus", "", true},
+		{"europe/london", "", true},
+		{"Local", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.zone, func(t *testing.T) {
+			before := chats.get(chat.ID).Timezone
+			err := chats.set(chat.ID, "tz", tt.zone)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidOption) {
+					t.Errorf("set(tz, %s) = %v, want %v", tt.zone, err, ErrInvalidOption)
+				}
+				if got := chats.get(chat.ID).Timezone; got != before {
+					t.Errorf("rejected zone changed the timezone to %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("set(tz, %s) = %v", tt.zone, err)
+			}
+			if got := formatTime(at, chats.get(chat.ID).location()); got != tt.want {
+				t.Errorf("timestamp in %s = %q, want %q", tt.zone, got, tt.want)
+			}
+		})
+	}
+}
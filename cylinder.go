@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/tucnak/telebot"
+)
+
+// renderCylinder draws the chambers of the current cylinder, the ones already
+// fired filled in. Loaded chambers are of course not shown.
+func renderCylinder(g *Game) string {
+	var b strings.Builder
+	b.WriteString("🔫 ")
+	for i := 0; i < g.Chambers; i++ {
+		if i < g.PullCount {
+			b.WriteString("⚫")
+		} else {
+			b.WriteString("⚪")
+		}
+	}
+
+	pulls := 0
+	for _, e := range g.Events {
+		if e.Kind == EventPull {
+			pulls++
+		}
+	}
+	// The pull count also keeps the text changing, which Telegram requires of an edit
+	fmt.Fprintf(&b, "\n%d of %d chambers fired, %d pull(s) survived so far", g.PullCount, g.Chambers, pulls)
+	return b.String()
+}
+
+// showCylinder brings the game's live cylinder message up to date, editing it
+// in place and only sending a new one when there is none yet or the edit fails
+func showCylinder(s Sender, chat *telebot.Chat, game *Game) {
+	text := renderCylinder(game)
+	if game.cylinderMsg != nil {
+		_, err := s.Edit(game.cylinderMsg, text)
+		if err == nil {
+			return
+		}
+		log.Printf("Error editing the cylinder in chat %d, sending a new one: %v", chat.ID, err)
+	}
+	postCylinder(s, chat, game, text)
+}
+
+// postCylinder sends text as a new live cylinder message for the game
+func postCylinder(s Sender, chat *telebot.Chat, game *Game, text string) {
+	msg, err := s.Send(chat, text)
+	if err != nil || msg == nil {
+		return
+	}
+	game.cylinderMsg = msg
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCylinderMessageIsEdited(t *testing.T) {
+	chat := testChat(t)
+	game := playChat(t, chat, defaultConfig(), lastInChamber())
+	s := &recordingSender{}
+
+	playPull(s, chat, game, "alice")
+	if posted := s.sentWith("🔫 "); len(posted) != 1 || len(s.edits) != 0 {
+		t.Fatalf("first pull sent %d cylinders and made %d edits, want 1 and 0", len(posted), len(s.edits))
+	}
+
+	playPull(s, chat, game, "alice")
+	playPass(s, chat, game, "alice")
+	playPull(s, chat, game, "bob")
+	if posted := s.sentWith("🔫 "); len(posted) != 1 {
+		t.Errorf("later pulls sent %d cylinders, want them edited instead", len(posted)-1)
+	}
+	if len(s.edits) != 2 {
+		t.Fatalf("made %d edits for two more pulls, want 2", len(s.edits))
+	}
+	if want := "🔫 ⚫⚫⚫⚪⚪⚪"; !strings.HasPrefix(s.edits[1], want) {
+		t.Errorf("cylinder after three pulls = %q, want it to start %q", s.edits[1], want)
+	}
+}
+
+func TestCylinderFallsBackToSending(t *testing.T) {
+	chat := testChat(t)
+	game := playChat(t, chat, defaultConfig(), lastInChamber())
+	s := &recordingSender{}
+
+	playPull(s, chat, game, "alice")
+	s.editErr = errors.New("message to edit not found")
+	playPull(s, chat, game, "alice")
+
+	if posted := s.sentWith("🔫 "); len(posted) != 2 {
+		t.Errorf("sent %d cylinders, want a new one when the edit fails", len(posted))
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dashboardPageSize is how many games one /dashboard page lists
+const dashboardPageSize = 10
+
+// renderDashboard lists one page of the active games across every chat, as
+// the chats last published them
+func renderDashboard(page int, now time.Time) string {
+	games := publishedGames()
+	ids := make([]int64, 0, len(games))
+	for id := range games {
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return "📋 No active games."
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	pages := (len(ids) + dashboardPageSize - 1) / dashboardPageSize
+	if page > pages {
+		page = pages
+	}
+	start := (page - 1) * dashboardPageSize
+	end := min(start+dashboardPageSize, len(ids))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📋 %d active game(s), page %d/%d", len(ids), page, pages)
+	for _, id := range ids[start:end] {
+		game := games[id]
+		fmt.Fprintf(&b, "\n\nChat %d: %s mode, %d player(s), %s old", id, game.Mode, len(game.Players), formatDuration(now.Sub(game.Created)))
+		if game.Phase == PhaseLobby {
+			b.WriteString("\nIn the lobby")
+		} else {
+			fmt.Fprintf(&b, "\nWaiting for %s", game.Waiting)
+		}
+	}
+	if page < pages {
+		fmt.Fprintf(&b, "\n\nUse /dashboard %d for more.", page+1)
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package main
+
+// recentMessageLimit is how many message IDs are remembered per chat
+const recentMessageLimit = 64
+
+// messageLog remembers a chat's recently handled message and button press IDs
+// so an update that Telegram delivers twice only changes the game once. It is
+// part of the chat's room.
+type messageLog struct {
+	ids     []int
+	presses []string
+}
+
+// seen reports whether the message was already handled, recording it if not
+func (l *messageLog) seen(msgID int) bool {
+	for _, id := range l.ids {
+		if id == msgID {
+			return true
+		}
+	}
+
+	if len(l.ids) >= recentMessageLimit {
+		l.ids = l.ids[1:]
+	}
+	l.ids = append(l.ids, msgID)
+	return false
+}
+
+// seenPress reports whether the button press was already handled, recording it if not
+func (l *messageLog) seenPress(pressID string) bool {
+	for _, id := range l.presses {
+		if id == pressID {
+			return true
+		}
+	}
+
+	if len(l.presses) >= recentMessageLimit {
+		l.presses = l.presses[1:]
+	}
+	l.presses = append(l.presses, pressID)
+	return false
+}
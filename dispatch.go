@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/tucnak/telebot"
+)
+
+// guard wraps a command handler with the checks every incoming message has to
+// pass before it may touch a game
+func guard(me *telebot.User, handler func(*telebot.Message)) func(*telebot.Message) {
+	return func(m *telebot.Message) {
+		if ignoreMessage(me, m) {
+			return
+		}
+		handler(m)
+	}
+}
+
+// ignoreMessage reports whether a message must not be handled: messages with
+// no sender, such as channel posts, the bot's own messages fed back to it,
+// which could otherwise loop or change state on nobody's behalf, edited
+// messages, so editing an old message into /pull can't replay a turn, and
+// commands meant for another bot or lacking the @suffix a group requires
+func ignoreMessage(me *telebot.User, m *telebot.Message) bool {
+	if m.Sender == nil || m.Sender.ID == me.ID || m.LastEdit != 0 {
+		return true
+	}
+
+	suffix := commandSuffix(m.Text)
+	if suffix != "" {
+		return !strings.EqualFold(suffix, me.Username)
+	}
+	if m.Chat.Type == telebot.ChatPrivate {
+		return false
+	}
+	return chats.get(m.Chat.ID).RequireSuffix
+}
+
+// commandSuffix returns the bot username a command is addressed to, as in
+// /pull@MyBot, or "" when it has none
+func commandSuffix(text string) string {
+	command, _, _ := strings.Cut(text, " ")
+	command, _, _ = strings.Cut(command, "\n")
+	_, suffix, _ := strings.Cut(command, "@")
+	return suffix
+}
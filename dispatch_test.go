@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/tucnak/telebot"
+)
+
+// testBotUser is the bot's own account in the dispatch tests
+var testBotUser = &telebot.User{ID: 1000, Username: "RouletteBot"}
+
+func TestGuardIgnoresOwnMessages(t *testing.T) {
+	chat := testChat(t)
+	tests := []struct {
+		name    string
+		sender  *telebot.User
+		handled bool
+	}{
+		{"player", &telebot.User{ID: 1, Username: "alice"}, true},
+		{"the bot itself", testBotUser, false},
+		{"channel post", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled := false
+			handler := guard(testBotUser, func(*telebot.Message) { handled = true })
+			handler(&telebot.Message{ID: 1, Chat: chat, Sender: tt.sender, Text: "/pull"})
+			if handled != tt.handled {
+				t.Errorf("handled = %t, want %t", handled, tt.handled)
+			}
+		})
+	}
+}
+
+func TestCommandSuffix(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"/pull", ""},
+		{"/pull@RouletteBot", "RouletteBot"},
+		{"/create@RouletteBot 8 2", "RouletteBot"},
+		{"/pull@OtherBot\nnow", "OtherBot"},
+		{"/compare @alice @bob", ""},
+	}
+	for _, tt := range tests {
+		if got := commandSuffix(tt.text); got != tt.want {
+			t.Errorf("commandSuffix(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoreMessageSuffix(t *testing.T) {
+	group := testChat(t)
+	private := &telebot.Chat{ID: 5, Type: telebot.ChatPrivate}
+	strict := &telebot.Chat{ID: group.ID - 1, Type: telebot.ChatSuperGroup}
+	if err := chats.set(strict.ID, "suffix", "on"); err != nil {
+		t.Fatalf("set(suffix, on) = %v", err)
+	}
+	alice := &telebot.User{ID: 1, Username: "alice"}
+
+	tests := []struct {
+		name   string
+		chat   *telebot.Chat
+		text   string
+		ignore bool
+	}{
+		{"plain command in a group", group, "/pull", false},
+		{"addressed to this bot", group, "/pull@RouletteBot", false},
+		{"suffix in another case", group, "/pull@roulettebot", false},
+		{"addressed to another bot", group, "/pull@OtherBot", true},
+		{"plain command where the suffix is required", strict, "/pull", true},
+		{"suffixed where it is required", strict, "/pull@RouletteBot", false},
+		{"another bot where it is required", strict, "/pull@OtherBot", true},
+		{"plain command in private", private, "/pull", false},
+		{"another bot in private", private, "/pull@OtherBot", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &telebot.Message{ID: 1, Chat: tt.chat, Sender: alice, Text: tt.text}
+			if got := ignoreMessage(testBotUser, m); got != tt.ignore {
+				t.Errorf("ignoreMessage(%q) = %t, want %t", tt.text, got, tt.ignore)
+			}
+		})
+	}
+}
+
+func TestEditedCommandsAreIgnored(t *testing.T) {
+	chat := testChat(t)
+	game := playChat(t, chat, defaultConfig(), lastInChamber())
+	s := &recordingSender{}
+	alice := &telebot.User{ID: 1, Username: "alice"}
+
+	// The /pull handler behind the guard every handler has
+	pull := guard(testBotUser, func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+		if game, err := activeGame(m.Chat.ID); err == nil {
+			playPull(s, m.Chat, game, "alice")
+		}
+	})
+
+	pull(&telebot.Message{ID: 7, Chat: chat, Sender: alice, Text: "/pull", LastEdit: 1700000000})
+	if game.PullCount != 0 || len(s.sent) != 0 {
+		t.Fatalf("an edited /pull was played: %d pulls, sent %q", game.PullCount, s.sent)
+	}
+
+	pull(&telebot.Message{ID: 8, Chat: chat, Sender: alice, Text: "/pull"})
+	if game.PullCount != 1 {
+		t.Errorf("PullCount after a fresh /pull = %d, want 1", game.PullCount)
+	}
+}
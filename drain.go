@@ -0,0 +1,81 @@
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+// drainTimeout is how long a shutdown waits for running games to finish
+const drainTimeout = 10 * time.Minute
+
+// draining is set while the bot finishes its games without starting new ones,
+// before a deploy or while it shuts down
+var draining atomic.Bool
+
+// maxActiveGames caps how many games can be going on at once, 0 for no cap
+var maxActiveGames int
+
+// loadMaxActiveGames reads MAX_ACTIVE_GAMES. Without it there is no cap.
+func loadMaxActiveGames() {
+	value := os.Getenv("MAX_ACTIVE_GAMES")
+	if value == "" {
+		return
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Printf("Invalid MAX_ACTIVE_GAMES %q, not capping games", value)
+		return
+	}
+	maxActiveGames = n
+}
+
+// refuseNewGame tells the chat no game can be created while draining or at
+// capacity and reports whether it did. A chat that just had a game may always
+// start its next one. Games are counted as the chats published them, so chats
+// creating a game at the same moment may take the bot a game or two past its
+// cap. The caller has the chat locked.
+func refuseNewGame(s Sender, chat *telebot.Chat) bool {
+	if draining.Load() {
+		s.Send(chat, "🛠 Bot is in maintenance, no new games can be started right now. Running games can still be finished.")
+		return true
+	}
+
+	r := roomOf(chat.ID)
+	hadGame := r.game != nil || r.finished != nil
+	if maxActiveGames > 0 && !hadGame && activeGames() >= maxActiveGames {
+		s.Send(chat, "The bot is at capacity, try again later.")
+		return true
+	}
+	return false
+}
+
+// activeGames counts the games that are still being played
+func activeGames() int {
+	return len(publishedGames())
+}
+
+// stopOnSignal drains the bot on SIGINT or SIGTERM and stops it once every
+// game has finished or drainTimeout has passed
+func stopOnSignal(bot *telebot.Bot) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		<-signals
+		draining.Store(true)
+		log.Printf("Shutting down, waiting up to %v for %d game(s) to finish", drainTimeout, activeGames())
+
+		deadline := time.Now().Add(drainTimeout)
+		for activeGames() > 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Second)
+		}
+		bot.Stop()
+	}()
+}
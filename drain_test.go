@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/tucnak/telebot"
+)
+
+func TestDrainingRefusesNewGames(t *testing.T) {
+	chat := testChat(t)
+	game := playChat(t, chat, defaultConfig(), lastInChamber())
+	other := &telebot.Chat{ID: chat.ID - 1, Type: telebot.ChatGroup}
+	draining.Store(true)
+	t.Cleanup(func() { draining.Store(false) })
+	s := &recordingSender{}
+
+	// /create in a chat without a game
+	r := lockChat(other.ID)
+	refused := refuseNewGame(s, other)
+	r.unlock()
+	if !refused {
+		t.Fatal("a new game was allowed while draining")
+	}
+	if len(s.sentWith("Bot is in maintenance")) != 1 {
+		t.Errorf("sent %q, want the maintenance notice", s.sent)
+	}
+
+	// The running game plays on
+	playPull(s, chat, game, "alice")
+	if game.PullCount != 1 {
+		t.Errorf("PullCount while draining = %d, want 1", game.PullCount)
+	}
+
+	draining.Store(false)
+	r = lockChat(other.ID)
+	refused = refuseNewGame(s, other)
+	r.unlock()
+	if refused {
+		t.Error("a new game was refused after undraining")
+	}
+}
+
+func TestMaxActiveGames(t *testing.T) {
+	first := testChat(t)
+	second := &telebot.Chat{ID: first.ID - 1, Type: telebot.ChatGroup}
+	old := maxActiveGames
+	maxActiveGames = 1
+	t.Cleanup(func() { maxActiveGames = old })
+	s := &recordingSender{}
+
+	// create runs /create's check and creates the game if it passes
+	create := func(chat *telebot.Chat) bool {
+		r := lockChat(chat.ID)
+		defer r.unlock()
+		if refuseNewGame(s, chat) {
+			return false
+		}
+		createGame(chat, "alice", "Alice", defaultConfig())
+		return true
+	}
+
+	if !create(first) {
+		t.Fatal("the first game was refused")
+	}
+	if create(second) {
+		t.Fatal("a game past the cap was created")
+	}
+	if len(s.sentWith("The bot is at capacity, try again later.")) != 1 {
+		t.Errorf("sent %q, want the capacity notice", s.sent)
+	}
+	// A chat that has a game may recreate it
+	if !create(first) {
+		t.Error("the chat with the game couldn't recreate it")
+	}
+
+	r := lockChat(first.ID)
+	r.game.IsActive = false
+	r.game = nil
+	r.unlock()
+	if !create(second) {
+		t.Error("a game was refused after the other one ended")
+	}
+}
+
+func TestLoadMaxActiveGames(t *testing.T) {
+	old := maxActiveGames
+	t.Cleanup(func() { maxActiveGames = old })
+
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{"", 0},
+		{"25", 25},
+		{"0", 0},
+		{"-3", 0},
+		{"many", 0},
+	}
+	for _, tt := range tests {
+		maxActiveGames = 0
+		t.Setenv("MAX_ACTIVE_GAMES", tt.value)
+		loadMaxActiveGames()
+		if maxActiveGames != tt.want {
+			t.Errorf("MAX_ACTIVE_GAMES=%q caps games at %d, want %d", tt.value, maxActiveGames, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+// duelTimeout is how long the opponent has to /accept a duel
+const duelTimeout = 60 * time.Second
+
+// challenge is a pending /duel waiting for the opponent to /accept
+type challenge struct {
+	Challenger     string
+	ChallengerName string
+	Opponent       string // Who was challenged, as mentioned
+	timer          *time.Timer
+}
+
+// inAnyGame reports whether the player is in an active game in any chat, as
+// far as the chats have published their games
+func inAnyGame(player string) bool {
+	for _, info := range publishedGames() {
+		if slices.Contains(info.Players, player) {
+			return true
+		}
+	}
+	return false
+}
+
+// challengeDuel records a duel between the challenger and opponent that
+// expires unless the opponent accepts in time. The caller has the chat locked.
+func challengeDuel(s Sender, chat *telebot.Chat, user *telebot.User, opponent string) {
+	challenger := getPlayerID(user)
+	r := roomOf(chat.ID)
+	if game := r.game; game != nil && game.IsActive {
+		s.Send(chat, "A game is already in progress!")
+		return
+	}
+	if refuseNewGame(s, chat) {
+		return
+	}
+	if r.challenge != nil {
+		s.Send(chat, "A duel is already waiting to be accepted!")
+		return
+	}
+	if answersTo(user, opponent) {
+		s.Send(chat, "You can't duel yourself!")
+		return
+	}
+	if inAnyGame(challenger) {
+		s.Send(chat, fmt.Sprintf("%s is already in a game!", displayName(user)))
+		return
+	}
+	if inAnyGame(stats.player(chat.ID, opponent)) {
+		s.Send(chat, fmt.Sprintf("@%s is already in a game!", opponent))
+		return
+	}
+
+	c := &challenge{Challenger: challenger, ChallengerName: displayName(user), Opponent: opponent}
+	c.timer = time.AfterFunc(duelTimeout, func() {
+		r := lockChat(chat.ID)
+		defer r.unlock()
+
+		if r.challenge != c {
+			return
+		}
+		r.challenge = nil
+		s.Send(chat, fmt.Sprintf("⌛ @%s didn't accept the duel in time.", opponent))
+	})
+	r.challenge = c
+
+	s.Send(chat, fmt.Sprintf("⚔️ %s challenges @%s to a duel!\n@%s, use /accept within %v to start.",
+		c.ChallengerName, opponent, opponent, duelTimeout))
+}
+
+// acceptDuel starts the chat's pending duel if player is the one challenged.
+// The caller has the chat locked.
+func acceptDuel(s Sender, chat *telebot.Chat, user *telebot.User) {
+	player := getPlayerID(user)
+	r := roomOf(chat.ID)
+	c := r.challenge
+	if c == nil {
+		s.Send(chat, "There's no duel to accept! Use /duel @player to challenge someone.")
+		return
+	}
+	if !answersTo(user, c.Opponent) {
+		s.Send(chat, fmt.Sprintf("This duel is for @%s!", c.Opponent))
+		return
+	}
+
+	c.timer.Stop()
+	r.challenge = nil
+
+	if game := r.game; game != nil && game.IsActive {
+		s.Send(chat, "A game is already in progress!")
+		return
+	}
+	if refuseNewGame(s, chat) {
+		return
+	}
+	if inAnyGame(c.Challenger) || inAnyGame(player) {
+		s.Send(chat, "One of the duelists has joined another game in the meantime!")
+		return
+	}
+
+	cfg := chats.get(chat.ID).Defaults
+	cfg.Host = false
+	game := createGame(chat, c.Challenger, c.ChallengerName, cfg)
+	if err := game.Join(player, displayName(user)); err != nil {
+		s.Send(chat, errorMessage(err, game))
+		return
+	}
+
+	s.Send(chat, fmt.Sprintf("🤝 %s accepted the duel!", game.name(player)))
+	startGame(s, chat, game)
+}
@@ -0,0 +1,37 @@
+package main
+
+import "time"
+
+// EventKind is the type of a recorded game event
+type EventKind string
+
+const (
+	EventStart EventKind = "start"
+	EventPull  EventKind = "pull" // A survived pull
+	EventDeath EventKind = "death"
+	EventSkip  EventKind = "skip"
+	EventJam   EventKind = "jam"
+	EventPass  EventKind = "pass"
+	EventCheat EventKind = "cheat" // The house secretly moved the bullets
+	EventKick  EventKind = "kick"  // The creator removed the second to last player
+)
+
+// Event is something that happened during a game, kept for the end of game recap
+type Event struct {
+	Time     time.Time
+	Kind     EventKind
+	Player   string
+	Odds     float64 `json:",omitempty"` // Fatal odds in percent the pull was taken at
+	Assisted bool    `json:",omitempty"` // The creator pulled on the player's behalf
+}
+
+// recordPull records a pull, marking whether the creator made it for the player
+func (g *Game) recordPull(kind EventKind, player string, odds float64, assisted bool) {
+	g.record(kind, player, odds)
+	g.Events[len(g.Events)-1].Assisted = assisted
+}
+
+// record appends an event to the game's log
+func (g *Game) record(kind EventKind, player string, odds float64) {
+	g.Events = append(g.Events, Event{Time: time.Now(), Kind: kind, Player: player, Odds: odds})
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+
+	"github.com/tucnak/telebot"
+)
+
+// sendCSV uploads data as a CSV document named after name. telebot can only
+// upload files from disk, so it goes through a temporary file.
+func sendCSV(s Sender, chat *telebot.Chat, name string, data []byte) error {
+	f, err := os.CreateTemp("", name+"-*.csv")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Send(chat, &telebot.Document{File: telebot.FromDisk(f.Name()), FileName: name + ".csv"})
+	return err
+}
@@ -0,0 +1,42 @@
+package main
+
+// firstWeightScale keeps integer weights fine grained enough to divide
+const firstWeightScale = 10
+
+// firstPlayerWeight favours players who keep dying early and have rarely gone first
+func firstPlayerWeight(s PlayerStats) int {
+	w := firstWeightScale * (1 + s.Deaths + s.EarlyDeaths) / (1 + s.WentFirst)
+	if w < 1 {
+		return 1
+	}
+	return w
+}
+
+// weightedPick returns an index chosen with probability proportional to its weight
+func weightedPick(weights []int, intn func(n int) int) int {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		return intn(len(weights))
+	}
+
+	r := intn(total)
+	for i, w := range weights {
+		if r < w {
+			return i
+		}
+		r -= w
+	}
+	return len(weights) - 1
+}
+
+// pickFairFirst chooses the opening player of a game weighted by past luck in the chat
+func pickFairFirst(chatID int64, g *Game) int {
+	weights := make([]int, len(g.Players))
+	for i, player := range g.Players {
+		weights[i] = firstPlayerWeight(stats.get(chatID, player))
+	}
+	return weightedPick(weights, g.rng.Intn)
+}
@@ -0,0 +1,65 @@
+package main
+
+import "testing"
+
+func TestWeightedPick(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights []int
+		draw    int
+		want    int
+	}{
+		{"first weight", []int{3, 1, 6}, 0, 0},
+		{"end of first weight", []int{3, 1, 6}, 2, 0},
+		{"second weight", []int{3, 1, 6}, 3, 1},
+		{"third weight", []int{3, 1, 6}, 4, 2},
+		{"last draw", []int{3, 1, 6}, 9, 2},
+		{"zero weight is skipped", []int{2, 0, 2}, 2, 2},
+		{"all zero falls back to uniform", []int{0, 0, 0}, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intn := func(n int) int { return tt.draw % n }
+			if got := weightedPick(tt.weights, intn); got != tt.want {
+				t.Errorf("weightedPick(%v) with draw %d = %d, want %d", tt.weights, tt.draw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeightedPickProportions(t *testing.T) {
+	weights := []int{1, 3}
+	counts := make([]int, len(weights))
+	for draw := 0; draw < 4; draw++ {
+		counts[weightedPick(weights, func(int) int { return draw })]++
+	}
+	if counts[0] != 1 || counts[1] != 3 {
+		t.Errorf("every draw of weights %v picked %v, want [1 3]", weights, counts)
+	}
+}
+
+func TestFirstPlayerWeight(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats PlayerStats
+		want  int
+	}{
+		{"newcomer", PlayerStats{}, firstWeightScale},
+		{"unlucky", PlayerStats{Deaths: 2, EarlyDeaths: 1}, 4 * firstWeightScale},
+		{"often first", PlayerStats{WentFirst: 4}, firstWeightScale / 5},
+		{"never below one", PlayerStats{WentFirst: 100}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := firstPlayerWeight(tt.stats); got != tt.want {
+				t.Errorf("firstPlayerWeight(%+v) = %d, want %d", tt.stats, got, tt.want)
+			}
+		})
+	}
+
+	unlucky := firstPlayerWeight(PlayerStats{Deaths: 3, EarlyDeaths: 2})
+	lucky := firstPlayerWeight(PlayerStats{Wins: 3, WentFirst: 3})
+	if unlucky <= lucky {
+		t.Errorf("an unlucky player weighs %d, no more than a lucky one at %d", unlucky, lucky)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+const defaultFinishedRetention = 5 * time.Minute
+
+// finishedRetention is how long a finished game is kept, 0 to drop it at once
+var finishedRetention = defaultFinishedRetention
+
+// retainFinished keeps the game as the chat's finished game until the
+// retention runs out or another game in the chat finishes
+func retainFinished(chatID int64, game *Game) {
+	if finishedRetention == 0 {
+		return
+	}
+	roomOf(chatID).finished = game
+	time.AfterFunc(finishedRetention, func() {
+		r := lockChat(chatID)
+		defer r.unlock()
+
+		if r.finished == game {
+			r.finished = nil
+		}
+	})
+}
+
+// parseRetention parses FINISHED_GAME_RETENTION as a duration, "0" keeping no
+// finished games
+func parseRetention(value string) time.Duration {
+	if value == "" {
+		return defaultFinishedRetention
+	}
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention < 0 {
+		log.Printf("Invalid FINISHED_GAME_RETENTION %q, using %v", value, defaultFinishedRetention)
+		return defaultFinishedRetention
+	}
+	return retention
+}
+
+// seedMessage reports the seed of the chat's last finished game. The seed
+// replays a game's cylinder exactly, so it is never shown while a game is
+// still going on. The caller has the chat locked.
+func seedMessage(chatID int64) string {
+	r := roomOf(chatID)
+	if game := r.game; game != nil && game.IsActive {
+		return "🌱 The seed is only shown once the game has finished, it would give the bullets away."
+	}
+	if !r.seeded {
+		return "No game has finished here yet!"
+	}
+	return fmt.Sprintf("🌱 Last game seed: %d", r.lastSeed)
+}
+
+// rematch starts a new game in the chat with the settings and everyone who
+// played in prev, their bots included. The settings are the ones prev started
+// with, so cylinders changed by /reload or /voteharder don't carry over.
+// The caller has the chat locked.
+func rematch(s Sender, chat *telebot.Chat, prev *Game) {
+	roomOf(chat.ID).finished = nil
+	cfg := prev.StartConfig
+	if cfg.Chambers == 0 {
+		// Saved before games kept their starting settings
+		cfg = prev.GameConfig
+	}
+	game := createGame(chat, prev.Creator, prev.Names[prev.Creator], cfg)
+	for _, player := range prev.roster() {
+		if game.HasPlayer(player) {
+			continue
+		}
+		if err := game.Join(player, prev.Names[player]); err != nil {
+			log.Printf("Error seating %s for the rematch in chat %d: %v", player, chat.ID, err)
+			continue
+		}
+		if bp, isBot := prev.Bots[player]; isBot {
+			game.Bots[player] = bp
+		}
+	}
+
+	senderFor(s, game).Send(chat, fmt.Sprintf("🔁 Rematch! Same settings, same players: %s", game.nameList(game.Players)))
+	startGame(s, chat, game)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFinishedGameIsRetained(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention time.Duration
+		wait      time.Duration
+		kept      bool
+	}{
+		{"within the window", time.Hour, 0, true},
+		{"after the window", 20 * time.Millisecond, 200 * time.Millisecond, false},
+		{"no retention", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := finishedRetention
+			finishedRetention = tt.retention
+			t.Cleanup(func() { finishedRetention = old })
+
+			chat := testChat(t)
+			game := playChat(t, chat, defaultConfig(), &scriptedRandomizer{})
+			// The bullet is in the first chamber, so alice ends the game
+			playPull(&recordingSender{}, chat, game, "alice")
+			time.Sleep(tt.wait)
+
+			r := lockChat(chat.ID)
+			defer r.unlock()
+			if kept := r.finished == game; kept != tt.kept {
+				t.Fatalf("finished game kept = %t, want %t", kept, tt.kept)
+			}
+			want := ErrNoActiveGame
+			if tt.kept {
+				want = ErrGameJustEnded
+			}
+			if _, err := activeGame(chat.ID); !errors.Is(err, want) {
+				t.Errorf("activeGame() = %v, want %v", err, want)
+			}
+		})
+	}
+}
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", defaultFinishedRetention},
+		{"90s", 90 * time.Second},
+		{"0", 0},
+		{"-1m", defaultFinishedRetention},
+		{"soon", defaultFinishedRetention},
+	}
+	for _, tt := range tests {
+		if got := parseRetention(tt.value); got != tt.want {
+			t.Errorf("parseRetention(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSeedIsOnlyShownAfterTheGame(t *testing.T) {
+	chat := testChat(t)
+	if got := seedMessage(chat.ID); got != "No game has finished here yet!" {
+		t.Errorf("seed before any game = %q", got)
+	}
+
+	game := playChat(t, chat, defaultConfig(), newSeededRandomizer(42))
+	lobby := lobbyGame(t, defaultConfig(), newSeededRandomizer(43))
+	for _, g := range []*Game{lobby, game} {
+		roomOf(chat.ID).game = g
+		if got := seedMessage(chat.ID); strings.Contains(got, fmt.Sprint(g.Seed)) || !strings.Contains(got, "only shown once the game has finished") {
+			t.Errorf("seed during a game in phase %d = %q", g.Phase, got)
+		}
+	}
+
+	// Whoever pulls, the game ends on the sixth pull at the latest
+	for game.IsActive {
+		player := game.CurrentPlayer()
+		playPull(&recordingSender{}, chat, game, player)
+		if game.IsActive {
+			playPass(&recordingSender{}, chat, game, player)
+		}
+	}
+	if got := seedMessage(chat.ID); got != "🌱 Last game seed: 42" {
+		t.Errorf("seed after the game = %q, want 42", got)
+	}
+}
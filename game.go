@@ -0,0 +1,802 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+const (
+	defaultChambers = 6
+	minChambers     = 2
+	maxChambers     = 20
+	defaultSkips    = 2
+	minPlayers      = 2
+	maxPlayers      = 50
+	maxReloads      = 1 // Fresh cylinders players may ask for per game
+
+	// voteHarderThreshold is how many spectator votes add a bullet
+	voteHarderThreshold = 3
+)
+
+// Errors returned by the Game methods so handlers can tell the causes apart
+var (
+	ErrNotYourTurn        = errors.New("not your turn")
+	ErrAlreadyJoined      = errors.New("player already joined")
+	ErrNotInGame          = errors.New("player is not in the game")
+	ErrAlreadyPulled      = errors.New("already pulled this turn")
+	ErrMustPullFirst      = errors.New("must pull before passing")
+	ErrNoSkips            = errors.New("no skips remaining")
+	ErrNotEnoughPlayers   = errors.New("not enough players")
+	ErrGameNotStarted     = errors.New("game has not started")
+	ErrGameAlreadyStarted = errors.New("game has already started")
+	ErrGameOver           = errors.New("game is over")
+	ErrNoActiveGame       = errors.New("no active game")
+	ErrGameJustEnded      = errors.New("game just ended")
+	ErrUnknownMode        = errors.New("unknown game mode")
+	ErrUnknownOption      = errors.New("unknown game option")
+	ErrInvalidOption      = errors.New("invalid game option")
+	ErrUnknownStrategy    = errors.New("unknown bot strategy")
+	ErrNotSpectator       = errors.New("only spectators can vote")
+	ErrAlreadyVoted       = errors.New("already voted")
+	ErrStaleGame          = errors.New("game was replaced")
+	ErrStaleTurn          = errors.New("turn is over")
+	ErrNoTurnTimer        = errors.New("turn timer is off")
+	ErrAlreadyExtended    = errors.New("turn already extended")
+	ErrNeedsConfirm       = errors.New("pull needs confirmation")
+	ErrNoConsent          = errors.New("player hasn't asked for assistance")
+	ErrEliminated         = errors.New("player was eliminated")
+	ErrGameFull           = errors.New("game is full")
+	ErrNoReloads          = errors.New("no reloads left")
+	ErrKicked             = errors.New("player was kicked")
+	ErrCylinderFull       = errors.New("no room for another bullet")
+)
+
+// Phase is the stage of a game's lifecycle
+type Phase int
+
+const (
+	PhaseLobby   Phase = iota // Players are joining
+	PhaseRunning              // Turns are being taken
+)
+
+// GameConfig holds the settings a game is played with
+type GameConfig struct {
+	Mode           Mode
+	SkipsPerPlayer int  // Skips each player starts with
+	SpinEach       bool // Spin the cylinder before every pull
+	FairStart      bool // Weight the first player towards those with worse luck
+	JamChance      int  // Percent chance that a skip jams and the player must pull
+	JamRefund      bool // A jammed skip is not used up
+	SafePulls      int  // The first pulls of each cylinder that can never be fatal
+	Reveal         bool // Tell players which chamber each survived pull cleared
+	Chambers       int  // Size of the cylinder
+	Bullets        int  // Bullets loaded into each cylinder
+	Host           bool // The creator runs the game without playing in it
+	HouseEdge      int  // Percent chance that the bullets are secretly moved before a pull
+	TurnTimeout    int  // Seconds a player has for their turn, 0 for no limit
+	ConfirmOdds    int  // Fatal odds in percent from which a pull must be confirmed, 0 for never
+	Practice       bool // Deaths only reload the cylinder and no stats are kept
+	ClutchOdds     int  // Fatal odds in percent from which a survived pull earns clutch points, 0 for none
+	JoinWindow     int  // Seconds the lobby stays open before the game starts by itself, 0 for no limit
+	JoinReminder   int  // Seconds before the join window closes that the chat is reminded
+	LateJoin       bool // Players may still join once the game is running
+	PeekSkip       bool // A skip privately tells the skipper whether the next chamber is loaded
+	Elimination    bool // A death only takes the player out, until one survivor is left
+	MaxPlayers     int  // Most players that can join, 0 for no limit
+	NoShuffle      bool // Play in join order instead of shuffling the players at the start
+}
+
+// Validate checks that the settings can be played with together
+func (cfg GameConfig) Validate() error {
+	if cfg.Chambers < minChambers || cfg.Chambers > maxChambers {
+		return fmt.Errorf("%w: chambers must be between %d and %d", ErrInvalidOption, minChambers, maxChambers)
+	}
+	if cfg.SafePulls < 0 || cfg.SafePulls >= cfg.Chambers {
+		return fmt.Errorf("%w: safepulls must be between 0 and %d", ErrInvalidOption, cfg.Chambers-1)
+	}
+	if cfg.MaxPlayers != 0 && (cfg.MaxPlayers < minPlayers || cfg.MaxPlayers > maxPlayers) {
+		return fmt.Errorf("%w: maxplayers must be between %d and %d", ErrInvalidOption, minPlayers, maxPlayers)
+	}
+	if cfg.Bullets < 1 || cfg.Bullets > cfg.Chambers-cfg.SafePulls {
+		return fmt.Errorf("%w: with %d chambers and %d safe pull(s) there is room for 1 to %d bullet(s)",
+			ErrInvalidOption, cfg.Chambers, cfg.SafePulls, cfg.Chambers-cfg.SafePulls)
+	}
+	return nil
+}
+
+type Game struct {
+	GameConfig
+	Generation      uint64 // Changes whenever the game is created or reloaded
+	Creator         string
+	Created         time.Time
+	Players         []string
+	Cylinder        []bool // Loaded chambers of the current cylinder
+	CurrentPos      int    // Index into Players of the current player, always kept in range
+	Turn            int    // Times the turn was handed on, telling one turn's buttons from the next
+	FullRound       bool   // Every player has had at least one turn
+	PullCount       int
+	IsActive        bool
+	Phase           Phase
+	Names           map[string]string    // Display name of each player
+	Skips           map[string]int       // Track remaining skips for each player
+	HasPulledOnTurn bool                 // Track if current player has pulled at least once on their turn
+	Bots            map[string]BotPlayer // Computer controlled players by player ID
+	Seed            int64                // Seed of rng when it has one, so an outcome can be reproduced
+	Events          []Event
+	HarderVotes     map[string]bool // Spectators who voted to add a bullet
+	PendingBullets  int             // Bullets to add at the next reload
+	PendingReload   *ReloadSettings // Cylinder to switch to at the next reload
+	AssistConsent   map[string]bool // Players who allowed the creator to /pullfor them
+	Eliminated      []string        // Players taken out in an elimination game, in the order they died
+	Reloads         int             // Times a player reloaded the cylinder with /reload
+	Kicked          map[string]bool `json:",omitempty"` // Players the creator removed, who can't join again
+	StartConfig     GameConfig      // Settings the game started with, before its mode or any reload changed them
+
+	rng          Randomizer
+	cylinderMsg  telebot.Editable  // The message showing the live cylinder, if one was sent
+	turnTimer    *time.Timer       // Runs out when the current player took too long
+	turnExtended bool              // The current turn's timer was already extended
+	confirming   bool              // The current player was warned and may confirm a dangerous pull
+	trigger      *telebot.Message  // The /pull message being played, to react to
+	lastNudge    time.Time         // When the current player was last nudged
+	joinTimers   []*time.Timer     // Reminder and closing of the join window
+	assisted     bool              // The pull being played is made by the creator for the player
+	badges       map[string]string // Leaderboard medals of the players, ranked when the game started
+	stopArmed    time.Time         // When a /stop was last asked for, awaiting confirmation
+	peeker       *telebot.User     // Who sent the /skip being played, to send the peek to
+}
+
+// ReloadSettings is a cylinder the creator asked for with /reload
+type ReloadSettings struct {
+	Chambers int
+	Bullets  int
+}
+
+// PullResult describes the outcome of a single trigger pull
+type PullResult struct {
+	Dead              bool
+	Eliminated        bool // The player died but the elimination game goes on without them
+	AddedBullets      int  // Bullets added by an escalation when the cylinder was reloaded
+	Chamber           int  // 1-based chamber that was fired
+	RemainingChambers int
+	Odds              float64 // Chance of the next pull being fatal, in percent
+	FacedOdds         float64 // Chance this pull had of being fatal, in percent
+}
+
+// lastGeneration is the generation most recently handed out. Every chat hands
+// them out, so it is atomic.
+var lastGeneration atomic.Uint64
+
+// nextGeneration returns a generation no game has had yet
+func nextGeneration() uint64 {
+	return lastGeneration.Add(1)
+}
+
+// newGame creates a game in the lobby phase with the creator as its first
+// player, unless they only host it. All of the game's randomness comes from rng.
+func newGame(creator, creatorName string, cfg GameConfig, rng Randomizer) *Game {
+	g := &Game{
+		GameConfig:    cfg,
+		Creator:       creator,
+		Created:       time.Now(),
+		Players:       []string{creator},
+		Names:         map[string]string{creator: creatorName},
+		IsActive:      true,
+		Phase:         PhaseLobby,
+		Skips:         map[string]int{creator: cfg.SkipsPerPlayer},
+		Bots:          make(map[string]BotPlayer),
+		HarderVotes:   make(map[string]bool),
+		AssistConsent: make(map[string]bool),
+		rng:           rng,
+	}
+	if cfg.Host {
+		g.Players = nil
+		delete(g.Skips, creator)
+	}
+	if s, ok := rng.(seeder); ok {
+		g.Seed = s.Seed()
+	}
+	g.reload()
+	return g
+}
+
+// CurrentPlayer returns the player whose turn it is
+func (g *Game) CurrentPlayer() string {
+	return g.Players[g.CurrentPos]
+}
+
+// HasPlayer reports whether the player has joined the game
+func (g *Game) HasPlayer(player string) bool {
+	for _, p := range g.Players {
+		if p == player {
+			return true
+		}
+	}
+	return false
+}
+
+// roster returns everyone who played in the game, eliminated players last
+func (g *Game) roster() []string {
+	return append(slices.Clone(g.Players), g.Eliminated...)
+}
+
+// eliminate takes the current player out of the game
+func (g *Game) eliminate(player string) {
+	g.removePlayer(player)
+	g.Eliminated = append(g.Eliminated, player)
+}
+
+// Kick removes a player from the game for good. If that leaves a running game
+// with fewer than two players it ends, and whoever is left wins.
+func (g *Game) Kick(player string) (ended bool, err error) {
+	if !g.IsActive {
+		return false, ErrGameOver
+	}
+	if !g.HasPlayer(player) {
+		return false, ErrNotInGame
+	}
+
+	g.removePlayer(player)
+	delete(g.AssistConsent, player)
+	if g.Kicked == nil {
+		g.Kicked = make(map[string]bool)
+	}
+	g.Kicked[player] = true
+
+	if g.Phase != PhaseRunning || len(g.Players) >= minPlayers {
+		return false, nil
+	}
+	g.IsActive = false
+	g.record(EventKick, player, 0)
+	return true, nil
+}
+
+// removePlayer takes player out of the turn order. If it was their turn it
+// passes to the player after them, who now sits at the same position.
+func (g *Game) removePlayer(player string) {
+	i := slices.Index(g.Players, player)
+	g.Players = slices.Delete(g.Players, i, i+1)
+	delete(g.Skips, player)
+
+	switch {
+	case g.Phase != PhaseRunning:
+	case i < g.CurrentPos:
+		g.CurrentPos--
+	case i == g.CurrentPos:
+		g.CurrentPos--
+		g.advance()
+	}
+}
+
+// survivors returns the players of the game other than the dead ones, in turn order
+func (g *Game) survivors(dead ...string) []string {
+	var survivors []string
+	for _, player := range g.Players {
+		if !slices.Contains(dead, player) {
+			survivors = append(survivors, player)
+		}
+	}
+	return survivors
+}
+
+// Join adds a player to a game that is still in the lobby, or to a running
+// one that allows late joins. A late joiner takes the last seat of the turn
+// order, so neither the cylinder nor anyone's place in the round changes.
+func (g *Game) Join(player, name string) error {
+	err := g.requirePhase(PhaseLobby)
+	if errors.Is(err, ErrGameAlreadyStarted) && g.LateJoin {
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+	if g.HasPlayer(player) {
+		return ErrAlreadyJoined
+	}
+	if slices.Contains(g.Eliminated, player) {
+		return ErrEliminated
+	}
+	if g.Kicked[player] {
+		return ErrKicked
+	}
+	if g.MaxPlayers > 0 && len(g.Players) >= g.MaxPlayers {
+		return ErrGameFull
+	}
+
+	g.Players = append(g.Players, player)
+	g.Names[player] = name
+	g.Skips[player] = g.SkipsPerPlayer
+	return nil
+}
+
+// Leave takes the player out of a game that is still in the lobby. Once it has
+// started it is refused, so the turn order is never broken mid-game. Like every
+// action it re-checks the game with the chat locked, so whichever of a /leave and a
+// /start comes first wins.
+func (g *Game) Leave(player string) error {
+	if !g.IsActive {
+		return ErrGameOver
+	}
+	if !g.HasPlayer(player) {
+		return ErrNotInGame
+	}
+	if err := g.requirePhase(PhaseLobby); err != nil {
+		return err
+	}
+
+	for i, p := range g.Players {
+		if p == player {
+			g.Players = append(g.Players[:i], g.Players[i+1:]...)
+			break
+		}
+	}
+	delete(g.Skips, player)
+	delete(g.Names, player)
+	delete(g.AssistConsent, player)
+	return nil
+}
+
+// SetMode selects the variant to play. It can only be changed in the lobby.
+func (g *Game) SetMode(mode Mode) error {
+	if err := g.requirePhase(PhaseLobby); err != nil {
+		return err
+	}
+	if _, ok := modes[mode]; !ok {
+		return ErrUnknownMode
+	}
+
+	g.Mode = mode
+	return nil
+}
+
+// Start moves the game from the lobby into play
+func (g *Game) Start() error {
+	if err := g.requirePhase(PhaseLobby); err != nil {
+		return err
+	}
+	if len(g.Players) < minPlayers {
+		return ErrNotEnoughPlayers
+	}
+
+	g.StartConfig = g.GameConfig
+	modes[g.Mode].apply(&g.GameConfig)
+	if g.SpinEach && g.SafePulls > 0 {
+		g.GameConfig = g.StartConfig
+		// Every pull starts a fresh cylinder, so nobody could ever die
+		return fmt.Errorf("%w: safepulls can't be used with %s mode", ErrInvalidOption, g.Mode)
+	}
+	if !g.NoShuffle {
+		g.shufflePlayers()
+	}
+	for _, player := range g.Players {
+		g.Skips[player] = g.SkipsPerPlayer
+	}
+
+	g.Phase = PhaseRunning
+	g.record(EventStart, "", 0)
+	return nil
+}
+
+// Restart returns a running game to the lobby with a fresh cylinder, keeping
+// the roster so the same players can /start again. The settings go back to
+// what they were before the game started, so neither its mode nor bullets
+// added during play stay behind, and a /mode changed in the lobby applies.
+func (g *Game) Restart() error {
+	if err := g.requirePhase(PhaseRunning); err != nil {
+		return err
+	}
+
+	if g.StartConfig.Chambers != 0 {
+		// Saved games from before StartConfig keep their settings
+		g.GameConfig = g.StartConfig
+	}
+	g.PendingBullets = 0
+	g.PendingReload = nil
+	g.HarderVotes = make(map[string]bool)
+	g.Kicked = nil
+	g.Generation = nextGeneration()
+	g.Phase = PhaseLobby
+	g.CurrentPos = 0
+	g.FullRound = false
+	g.HasPulledOnTurn = false
+	g.turnExtended = false
+	g.confirming = false
+	g.Events = nil
+	g.cylinderMsg = nil
+	g.Players = append(g.Players, g.Eliminated...)
+	g.Eliminated = nil
+	g.Reloads = 0
+	g.reload()
+	for _, player := range g.Players {
+		g.Skips[player] = g.SkipsPerPlayer
+	}
+	return nil
+}
+
+// Skip uses one of the current player's skips and passes the turn on. If the
+// skip jams the turn stays with the player, who then has to pull.
+func (g *Game) Skip(player string) (jammed bool, err error) {
+	if err := g.requireTurn(player); err != nil {
+		return false, err
+	}
+	if g.HasPulledOnTurn {
+		return false, ErrAlreadyPulled
+	}
+	if g.Skips[player] <= 0 {
+		return false, ErrNoSkips
+	}
+
+	if g.JamChance > 0 && g.rng.Intn(100) < g.JamChance {
+		if !g.JamRefund {
+			g.Skips[player]--
+		}
+		g.record(EventJam, player, 0)
+		return true, nil
+	}
+
+	g.Skips[player]--
+	g.record(EventSkip, player, 0)
+	g.advance()
+	return false, nil
+}
+
+// Pass ends the current player's turn after they have pulled at least once
+func (g *Game) Pass(player string) error {
+	if err := g.requireTurn(player); err != nil {
+		return err
+	}
+	if !g.HasPulledOnTurn {
+		return ErrMustPullFirst
+	}
+
+	g.record(EventPass, player, 0)
+	g.advance()
+	return nil
+}
+
+// AllowAssist records the player's consent to the creator pulling for them
+func (g *Game) AllowAssist(player string) error {
+	if !g.IsActive {
+		return ErrGameOver
+	}
+	if !g.HasPlayer(player) {
+		return ErrNotInGame
+	}
+
+	g.AssistConsent[player] = true
+	return nil
+}
+
+// CanAssist checks that the creator may pull for the player: it has to be the
+// player's turn and they must have consented
+func (g *Game) CanAssist(player string) error {
+	if err := g.requireTurn(player); err != nil {
+		return err
+	}
+	if !g.AssistConsent[player] {
+		return ErrNoConsent
+	}
+	return nil
+}
+
+// ConfirmPull checks whether the player may pull without confirming first. A
+// pull at odds of ConfirmOdds or more returns ErrNeedsConfirm until the player
+// confirms it after having been warned.
+func (g *Game) ConfirmPull(player string, confirmed bool) error {
+	if err := g.requireTurn(player); err != nil {
+		return err
+	}
+	if g.ConfirmOdds == 0 || g.NextOdds() < float64(g.ConfirmOdds) {
+		return nil
+	}
+	if confirmed && g.confirming {
+		g.confirming = false
+		return nil
+	}
+
+	g.confirming = true
+	return ErrNeedsConfirm
+}
+
+// Pull fires the next chamber for the current player. A fatal pull ends the game.
+func (g *Game) Pull(player string) (PullResult, error) {
+	assisted := g.assisted
+	g.assisted = false
+	if err := g.requireTurn(player); err != nil {
+		return PullResult{}, err
+	}
+
+	var added int
+	if g.SpinEach {
+		added = g.reload()
+	} else if g.PullCount > 0 && g.HouseEdge > 0 && g.rng.Intn(100) < g.HouseEdge {
+		g.reshuffle()
+		g.record(EventCheat, player, 0)
+	}
+
+	odds := g.NextOdds()
+	// A cylinder fired through without a death can only come from a bug, but
+	// the last chamber must have held the bullet, so the pull is fatal
+	if g.PullCount >= len(g.Cylinder) || g.Cylinder[g.PullCount] {
+		g.recordPull(EventDeath, player, odds, assisted)
+		if g.Practice {
+			// Nobody really dies in practice, the game goes on with a fresh cylinder
+			g.reload()
+			g.advance()
+			return PullResult{Dead: true}, nil
+		}
+		if g.Elimination {
+			g.eliminate(player)
+			if len(g.Players) > 1 {
+				g.reload()
+				return PullResult{Dead: true, Eliminated: true}, nil
+			}
+		}
+		g.IsActive = false
+		return PullResult{Dead: true}, nil
+	}
+
+	g.HasPulledOnTurn = true
+	g.PullCount++
+	g.recordPull(EventPull, player, odds, assisted)
+
+	return PullResult{
+		AddedBullets:      added,
+		FacedOdds:         odds,
+		Chamber:           g.PullCount,
+		RemainingChambers: g.remainingChambers(),
+		Odds:              g.NextOdds(),
+	}, nil
+}
+
+// NextOdds returns the chance, in percent, that the next pull is fatal
+func (g *Game) NextOdds() float64 {
+	if g.PullCount < g.SafePulls {
+		return 0
+	}
+	if g.remainingChambers() <= 0 {
+		return 100
+	}
+	return 100 * float64(g.remainingBullets()) / float64(g.remainingChambers())
+}
+
+// remainingBullets counts the bullets the next pull could land on
+func (g *Game) remainingBullets() int {
+	if g.SpinEach {
+		return g.Bullets
+	}
+	n := 0
+	for _, loaded := range g.Cylinder[g.PullCount:] {
+		if loaded {
+			n++
+		}
+	}
+	return n
+}
+
+// remainingChambers counts the chambers the next pull could land on
+func (g *Game) remainingChambers() int {
+	if g.SpinEach {
+		// The cylinder is spun again before the next pull, so it faces a full one
+		return g.Chambers
+	}
+	return g.Chambers - g.PullCount
+}
+
+// reload spins a fresh cylinder, first adding any bullets an escalation is
+// waiting on, and loads the bullets into random chambers outside the safe
+// region. It returns how many bullets the escalation added.
+func (g *Game) reload() int {
+	if g.PendingReload != nil {
+		g.Chambers, g.Bullets = g.PendingReload.Chambers, g.PendingReload.Bullets
+		g.PendingReload = nil
+	}
+
+	added := 0
+	for ; g.PendingBullets > 0 && g.Bullets < maxBullets(g.GameConfig); g.PendingBullets-- {
+		g.Bullets++
+		added++
+	}
+	g.PendingBullets = 0
+
+	g.loadCylinder()
+	return added
+}
+
+// loadCylinder spins a fresh cylinder of the current size and loads the
+// bullets into random chambers outside the safe region
+func (g *Game) loadCylinder() {
+	// Partial Fisher-Yates shuffle of the chambers the bullets may go in
+	chambers := make([]int, 0, g.Chambers-g.SafePulls)
+	for i := g.SafePulls; i < g.Chambers; i++ {
+		chambers = append(chambers, i)
+	}
+	g.Cylinder = make([]bool, g.Chambers)
+	for i := 0; i < g.Bullets; i++ {
+		j := i + g.rng.Intn(len(chambers)-i)
+		chambers[i], chambers[j] = chambers[j], chambers[i]
+		g.Cylinder[chambers[i]] = true
+	}
+
+	g.PullCount = 0
+}
+
+// SetReload schedules a different cylinder for the next reload, leaving the
+// current one alone. Empty settings in payload keep their current value.
+func (g *Game) SetReload(payload string) (ReloadSettings, error) {
+	if err := g.requirePhase(PhaseRunning); err != nil {
+		return ReloadSettings{}, err
+	}
+
+	cfg := g.GameConfig
+	if g.PendingReload != nil {
+		cfg.Chambers, cfg.Bullets = g.PendingReload.Chambers, g.PendingReload.Bullets
+	}
+	for _, opt := range strings.Fields(strings.ToLower(payload)) {
+		key, value, _ := strings.Cut(opt, "=")
+		if key != "chambers" && key != "bullets" {
+			return ReloadSettings{}, fmt.Errorf("%w: only chambers and bullets can be changed at a reload", ErrInvalidOption)
+		}
+		if err := applyValueOption(key, value, &cfg); err != nil {
+			return ReloadSettings{}, err
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return ReloadSettings{}, err
+	}
+
+	g.PendingReload = &ReloadSettings{Chambers: cfg.Chambers, Bullets: cfg.Bullets}
+	return *g.PendingReload, nil
+}
+
+// Respin lets the current player load a fresh cylinder before they pull, at
+// most maxReloads times per game so bad odds can't be dodged forever. It keeps
+// the cylinder's size and bullets, leaving a scheduled /reload and any voted
+// bullets for the next regular reload.
+func (g *Game) Respin(player string) error {
+	if err := g.requireTurn(player); err != nil {
+		return err
+	}
+	if g.HasPulledOnTurn {
+		return ErrAlreadyPulled
+	}
+	if g.Reloads >= maxReloads {
+		return ErrNoReloads
+	}
+
+	g.Reloads++
+	g.loadCylinder()
+	return nil
+}
+
+// reshuffle moves the bullets left in the cylinder to random unfired chambers
+// outside the safe region, without telling anyone. The odds stay the same.
+func (g *Game) reshuffle() {
+	bullets := g.remainingBullets()
+	chambers := make([]int, 0, g.Chambers)
+	for i := max(g.PullCount, g.SafePulls); i < g.Chambers; i++ {
+		chambers = append(chambers, i)
+		g.Cylinder[i] = false
+	}
+	for i := 0; i < bullets; i++ {
+		j := i + g.rng.Intn(len(chambers)-i)
+		chambers[i], chambers[j] = chambers[j], chambers[i]
+		g.Cylinder[chambers[i]] = true
+	}
+}
+
+// maxBullets is how many bullets escalation may load, always leaving an empty chamber
+func maxBullets(cfg GameConfig) int {
+	return min(cfg.Chambers-1, cfg.Chambers-cfg.SafePulls)
+}
+
+// VoteHarder records a spectator's vote to make the game more dangerous and
+// reports whether the vote reached the threshold and added an extra bullet.
+// Games that reload during play get it at the next reload. The others never
+// reload, so it goes into the current cylinder right away, as long as an
+// unfired chamber stays empty.
+func (g *Game) VoteHarder(voter string) (escalated bool, err error) {
+	if err := g.requirePhase(PhaseRunning); err != nil {
+		return false, err
+	}
+	if g.HasPlayer(voter) {
+		return false, ErrNotSpectator
+	}
+	if g.HarderVotes[voter] {
+		return false, ErrAlreadyVoted
+	}
+	if !g.reloadsDuringPlay() && len(g.emptyChambers()) < 2 {
+		return false, ErrCylinderFull
+	}
+
+	g.HarderVotes[voter] = true
+	if len(g.HarderVotes) < voteHarderThreshold {
+		return false, nil
+	}
+
+	g.HarderVotes = make(map[string]bool)
+	if g.reloadsDuringPlay() {
+		g.PendingBullets++
+		return true, nil
+	}
+	empty := g.emptyChambers()
+	g.Cylinder[empty[g.rng.Intn(len(empty))]] = true
+	g.Bullets++
+	return true, nil
+}
+
+// reloadsDuringPlay reports whether the cylinder is reloaded before the game
+// ends: before every pull, or after a death that doesn't end the game
+func (g *Game) reloadsDuringPlay() bool {
+	return g.SpinEach || g.Elimination || g.Practice
+}
+
+// emptyChambers returns the unfired chambers outside the safe region that
+// hold no bullet
+func (g *Game) emptyChambers() []int {
+	var empty []int
+	for i := max(g.PullCount, g.SafePulls); i < len(g.Cylinder); i++ {
+		if !g.Cylinder[i] {
+			empty = append(empty, i)
+		}
+	}
+	return empty
+}
+
+// shufflePlayers puts the players in a random turn order, so joining first
+// is no advantage
+func (g *Game) shufflePlayers() {
+	for i := len(g.Players) - 1; i > 0; i-- {
+		j := g.rng.Intn(i + 1)
+		g.Players[i], g.Players[j] = g.Players[j], g.Players[i]
+	}
+	g.CurrentPos = 0
+}
+
+// setFirstPlayer rotates the turn order so that the player at index i goes first
+func (g *Game) setFirstPlayer(i int) {
+	g.Players = append(g.Players[i:], g.Players[:i]...)
+	g.CurrentPos = 0
+}
+
+// advance hands the turn to the next player, wrapping around the roster
+func (g *Game) advance() {
+	g.CurrentPos++
+	if g.CurrentPos >= len(g.Players) {
+		g.CurrentPos = 0
+		g.FullRound = true
+	}
+	g.Turn++
+	g.HasPulledOnTurn = false
+	g.turnExtended = false
+	g.confirming = false
+}
+
+func (g *Game) requirePhase(phase Phase) error {
+	if !g.IsActive {
+		return ErrGameOver
+	}
+	if g.Phase == phase {
+		return nil
+	}
+	if phase == PhaseLobby {
+		return ErrGameAlreadyStarted
+	}
+	return ErrGameNotStarted
+}
+
+func (g *Game) requireTurn(player string) error {
+	if err := g.requirePhase(PhaseRunning); err != nil {
+		return err
+	}
+	if g.CurrentPlayer() != player {
+		return ErrNotYourTurn
+	}
+	return nil
+}
@@ -0,0 +1,876 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+// scriptedRandomizer returns its values in order, reduced to the range asked
+// for, and 0 once they run out
+type scriptedRandomizer struct {
+	values []int
+}
+
+func (r *scriptedRandomizer) Intn(n int) int {
+	if len(r.values) == 0 {
+		return 0
+	}
+	v := r.values[0]
+	r.values = r.values[1:]
+	return v % n
+}
+
+// lastInChamber loads a single bullet into the last of the default chambers
+func lastInChamber() Randomizer {
+	return &scriptedRandomizer{values: []int{defaultChambers - 1}}
+}
+
+// lobbyGame creates a game of alice, bob and carol in the lobby, playing in
+// join order. The randomizer decides where the bullets go.
+func lobbyGame(t *testing.T, cfg GameConfig, rng Randomizer) *Game {
+	t.Helper()
+	cfg.NoShuffle = true
+	g := newGame("alice", "Alice", cfg, rng)
+	for _, player := range []string{"bob", "carol"} {
+		if err := g.Join(player, player); err != nil {
+			t.Fatalf("Join(%s) = %v", player, err)
+		}
+	}
+	return g
+}
+
+// runningGame is lobbyGame after /start
+func runningGame(t *testing.T, cfg GameConfig, rng Randomizer) *Game {
+	t.Helper()
+	g := lobbyGame(t, cfg, rng)
+	if err := g.Start(); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	return g
+}
+
+func TestModesApplyAtStart(t *testing.T) {
+	tests := []struct {
+		mode  Mode
+		check func(g *Game) bool
+	}{
+		{ModeClassic, func(g *Game) bool { return !g.SpinEach && !g.Elimination && g.SkipsPerPlayer == defaultSkips }},
+		{ModeSpin, func(g *Game) bool { return g.SpinEach }},
+		{ModeHardcore, func(g *Game) bool { return g.SkipsPerPlayer == 0 && g.Skips["bob"] == 0 }},
+		{ModeElim, func(g *Game) bool { return g.Elimination }},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			g := lobbyGame(t, defaultConfig(), &scriptedRandomizer{})
+			if err := g.SetMode(tt.mode); err != nil {
+				t.Fatalf("SetMode(%s) = %v", tt.mode, err)
+			}
+			if err := g.Start(); err != nil {
+				t.Fatalf("Start() = %v", err)
+			}
+			if !tt.check(g) {
+				t.Errorf("%s mode didn't set its parameters: %+v", tt.mode, g.GameConfig)
+			}
+		})
+	}
+}
+
+func TestSetMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		started bool
+		mode    Mode
+		want    error
+	}{
+		{"lobby", false, ModeSpin, nil},
+		{"unknown", false, Mode("roulette"), ErrUnknownMode},
+		{"after start", true, ModeSpin, ErrGameAlreadyStarted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := lobbyGame(t, defaultConfig(), &scriptedRandomizer{})
+			if tt.started {
+				if err := g.Start(); err != nil {
+					t.Fatalf("Start() = %v", err)
+				}
+			}
+			if err := g.SetMode(tt.mode); !errors.Is(err, tt.want) {
+				t.Errorf("SetMode(%s) = %v, want %v", tt.mode, err, tt.want)
+			}
+			if tt.want != nil && g.Mode != ModeClassic {
+				t.Errorf("refused SetMode changed the mode to %s", g.Mode)
+			}
+		})
+	}
+}
+
+func TestSkipJam(t *testing.T) {
+	tests := []struct {
+		name       string
+		jamChance  int
+		refund     bool
+		roll       int
+		wantJammed bool
+		wantSkips  int
+	}{
+		{"no jam option", 0, false, 0, false, defaultSkips - 1},
+		{"roll above chance", 30, false, 30, false, defaultSkips - 1},
+		{"roll below chance", 30, false, 29, true, defaultSkips - 1},
+		{"jam refunded", 30, true, 0, true, defaultSkips},
+		{"certain jam", 100, false, 99, true, defaultSkips - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.JamChance, cfg.JamRefund = tt.jamChance, tt.refund
+			g := runningGame(t, cfg, &scriptedRandomizer{})
+			g.rng = &scriptedRandomizer{values: []int{tt.roll}}
+
+			jammed, err := g.Skip("alice")
+			if err != nil {
+				t.Fatalf("Skip() = %v", err)
+			}
+			if jammed != tt.wantJammed {
+				t.Errorf("jammed = %t, want %t", jammed, tt.wantJammed)
+			}
+			if g.Skips["alice"] != tt.wantSkips {
+				t.Errorf("skips left = %d, want %d", g.Skips["alice"], tt.wantSkips)
+			}
+
+			// A jam keeps the turn, so alice has to pull
+			wantPlayer := "bob"
+			if tt.wantJammed {
+				wantPlayer = "alice"
+			}
+			if g.CurrentPlayer() != wantPlayer {
+				t.Errorf("turn is %s's, want %s's", g.CurrentPlayer(), wantPlayer)
+			}
+		})
+	}
+}
+
+func TestSafePullsKeepBulletsOut(t *testing.T) {
+	tests := []struct {
+		chambers, bullets, safe int
+	}{
+		{6, 1, 2},
+		{6, 2, 3},
+		{8, 1, 7},
+		{4, 3, 1},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d chambers %d bullets %d safe", tt.chambers, tt.bullets, tt.safe), func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.Chambers, cfg.Bullets, cfg.SafePulls = tt.chambers, tt.bullets, tt.safe
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("Validate() = %v", err)
+			}
+
+			rng := newSeededRandomizer(1)
+			for i := 0; i < 1000; i++ {
+				g := newGame("alice", "Alice", cfg, rng)
+				loaded := 0
+				for chamber, bullet := range g.Cylinder {
+					if bullet && chamber < tt.safe {
+						t.Fatalf("game %d loaded safe chamber %d: %v", i, chamber+1, g.Cylinder)
+					}
+					if bullet {
+						loaded++
+					}
+				}
+				if loaded != tt.bullets {
+					t.Fatalf("game %d loaded %d bullets, want %d", i, loaded, tt.bullets)
+				}
+				if odds := g.NextOdds(); odds != 0 {
+					t.Fatalf("first pull of game %d is %.1f%% likely to be fatal, want 0", i, odds)
+				}
+			}
+		})
+	}
+}
+
+func TestSafePullsValidation(t *testing.T) {
+	tests := []struct {
+		safe, bullets int
+		valid         bool
+	}{
+		{0, 1, true},
+		{5, 1, true},
+		{6, 1, false},
+		{-1, 1, false},
+		{4, 2, true},
+		{4, 3, false},
+	}
+	for _, tt := range tests {
+		cfg := defaultConfig()
+		cfg.SafePulls, cfg.Bullets = tt.safe, tt.bullets
+		err := cfg.Validate()
+		if (err == nil) != tt.valid || (err != nil && !errors.Is(err, ErrInvalidOption)) {
+			t.Errorf("Validate() with %d safe pulls and %d bullets = %v, want valid %t", tt.safe, tt.bullets, err, tt.valid)
+		}
+	}
+}
+
+func TestVoteHarder(t *testing.T) {
+	g := runningGame(t, defaultConfig(), lastInChamber())
+
+	tests := []struct {
+		voter         string
+		wantEscalated bool
+		wantErr       error
+	}{
+		{"alice", false, ErrNotSpectator},
+		{"dave", false, nil},
+		{"dave", false, ErrAlreadyVoted},
+		{"erin", false, nil},
+		{"frank", true, nil},
+		{"dave", false, nil}, // Votes start over once they added a bullet
+	}
+	for _, tt := range tests {
+		escalated, err := g.VoteHarder(tt.voter)
+		if escalated != tt.wantEscalated || !errors.Is(err, tt.wantErr) {
+			t.Errorf("VoteHarder(%s) = %t, %v, want %t, %v", tt.voter, escalated, err, tt.wantEscalated, tt.wantErr)
+		}
+	}
+}
+
+func TestVoteHarderLoadsABullet(t *testing.T) {
+	tests := []struct {
+		mode        Mode
+		wantLoaded  int // Bullets in the cylinder once the vote passed
+		wantPending int
+	}{
+		// Nothing is reloaded before the game ends, so the bullet goes in now
+		{ModeClassic, 2, 0},
+		{ModeHardcore, 2, 0},
+		{ModeSpin, 1, 1},
+		{ModeElim, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			g := lobbyGame(t, defaultConfig(), lastInChamber())
+			g.SetMode(tt.mode)
+			if err := g.Start(); err != nil {
+				t.Fatalf("Start() = %v", err)
+			}
+			for _, voter := range []string{"dave", "erin", "frank"} {
+				if _, err := g.VoteHarder(voter); err != nil {
+					t.Fatalf("VoteHarder(%s) = %v", voter, err)
+				}
+			}
+			if g.remainingBullets() != tt.wantLoaded || g.Bullets != tt.wantLoaded || g.PendingBullets != tt.wantPending {
+				t.Fatalf("after the vote %d bullet(s) loaded, %d counted and %d pending, want %d loaded and %d pending",
+					g.remainingBullets(), g.Bullets, g.PendingBullets, tt.wantLoaded, tt.wantPending)
+			}
+			if tt.wantPending == 0 {
+				return
+			}
+			if added := g.reload(); added != 1 || g.Bullets != 2 || g.PendingBullets != 0 {
+				t.Errorf("reload() added %d, leaving %d bullets and %d pending, want 1, 2 and 0", added, g.Bullets, g.PendingBullets)
+			}
+		})
+	}
+}
+
+func TestVoteHarderNeedsAnEmptyChamber(t *testing.T) {
+	g := runningGame(t, defaultConfig(), lastInChamber())
+	// Chambers 5 and 6 are left, the last one loaded
+	g.PullCount = 4
+	if _, err := g.VoteHarder("dave"); !errors.Is(err, ErrCylinderFull) {
+		t.Errorf("VoteHarder() with one empty chamber left = %v, want %v", err, ErrCylinderFull)
+	}
+	if len(g.HarderVotes) != 0 || g.Bullets != 1 {
+		t.Errorf("a refused vote was counted: %v, %d bullet(s)", g.HarderVotes, g.Bullets)
+	}
+
+	g.PullCount = 3
+	for _, voter := range []string{"dave", "erin", "frank"} {
+		if _, err := g.VoteHarder(voter); err != nil {
+			t.Fatalf("VoteHarder(%s) = %v", voter, err)
+		}
+	}
+	if g.Cylinder[3] == g.Cylinder[4] || !g.Cylinder[5] {
+		t.Errorf("cylinder after the vote = %v, want one of chambers 4 and 5 loaded next to 6", g.Cylinder)
+	}
+}
+
+func TestVoteHarderNeedsRunningGame(t *testing.T) {
+	g := lobbyGame(t, defaultConfig(), lastInChamber())
+	if _, err := g.VoteHarder("dave"); !errors.Is(err, ErrGameNotStarted) {
+		t.Errorf("VoteHarder() in the lobby = %v, want %v", err, ErrGameNotStarted)
+	}
+}
+
+func TestHostedGame(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Host, cfg.NoShuffle = true, true
+	g := newGame("1", "Alice", cfg, lastInChamber())
+
+	if g.HasPlayer("1") || len(g.Players) != 0 {
+		t.Fatalf("players of a hosted game = %v, want none", g.Players)
+	}
+	if _, ok := g.Skips["1"]; ok {
+		t.Error("the host got skips")
+	}
+	if g.Creator != "1" {
+		t.Errorf("Creator = %s, want the host", g.Creator)
+	}
+
+	g.Join("bob", "Bob")
+	if err := g.Start(); !errors.Is(err, ErrNotEnoughPlayers) {
+		t.Fatalf("Start() with one player = %v, want %v", err, ErrNotEnoughPlayers)
+	}
+	g.Join("carol", "Carol")
+	if err := g.Start(); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if _, err := g.Pull("1"); !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("the host pulled: %v", err)
+	}
+
+	// Hosting still lets alice manage the game
+	if !canManageGame(nil, nil, &telebot.User{ID: 1, Username: "alice"}, g.Creator) {
+		t.Error("the host can't manage their game")
+	}
+	if ended, err := g.Kick("bob"); err != nil || !ended {
+		t.Errorf("Kick(bob) by the host = %t, %v, want the game to end", ended, err)
+	}
+}
+
+func TestEmptiedCylinderForcesDeath(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(g *Game)
+	}{
+		{"every chamber fired", func(g *Game) { g.PullCount = g.Chambers }},
+		{"cylinder lost its bullet", func(g *Game) { g.Cylinder = make([]bool, g.Chambers); g.PullCount = g.Chambers }},
+		{"count past the cylinder", func(g *Game) { g.PullCount = g.Chambers + 3 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := runningGame(t, defaultConfig(), lastInChamber())
+			tt.corrupt(g)
+
+			if odds := g.NextOdds(); odds != 100 {
+				t.Errorf("NextOdds() = %.1f, want 100", odds)
+			}
+			result, err := g.Pull("alice")
+			if err != nil || !result.Dead {
+				t.Errorf("Pull() = %+v, %v, want a death", result, err)
+			}
+			if g.IsActive {
+				t.Error("the game goes on after the forced death")
+			}
+		})
+	}
+}
+
+// countEvents counts the game's events of kind
+func countEvents(g *Game, kind EventKind) int {
+	n := 0
+	for _, e := range g.Events {
+		if e.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+func TestHouseEdgeScripted(t *testing.T) {
+	tests := []struct {
+		name      string
+		edge      int
+		roll      int
+		wantCheat bool
+	}{
+		{"roll below the edge", 20, 19, true},
+		{"roll at the edge", 20, 20, false},
+		{"no edge", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.Chambers, cfg.Bullets, cfg.HouseEdge = 10, 2, tt.edge
+			// Bullets in the last two chambers, so alice survives her pulls
+			g := runningGame(t, cfg, &scriptedRandomizer{values: []int{9, 7}})
+			if _, err := g.Pull("alice"); err != nil {
+				t.Fatal(err)
+			}
+			odds := g.NextOdds()
+
+			// The reshuffle puts both bullets in the first unfired chambers
+			g.rng = &scriptedRandomizer{values: []int{tt.roll}}
+			result, err := g.Pull("alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cheated := countEvents(g, EventCheat) == 1; cheated != tt.wantCheat {
+				t.Fatalf("cheated = %t, want %t", cheated, tt.wantCheat)
+			}
+			if tt.wantCheat && !result.Dead {
+				t.Errorf("the moved bullet wasn't fired by the next pull: %v", g.Cylinder)
+			}
+			if !tt.wantCheat && result.FacedOdds != odds {
+				t.Errorf("faced odds %.1f, want %.1f", result.FacedOdds, odds)
+			}
+		})
+	}
+}
+
+func TestHouseEdgeRate(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Chambers, cfg.HouseEdge = 20, 25
+	rng := newSeededRandomizer(3)
+
+	const games = 4000
+	cheats := 0
+	for i := 0; i < games; i++ {
+		g := runningGame(t, cfg, rng)
+		g.Cylinder = make([]bool, g.Chambers)
+		g.Cylinder[g.Chambers-1] = true
+		g.Pull("alice")
+		bullets := g.remainingBullets()
+		g.Pull("alice")
+		cheats += countEvents(g, EventCheat)
+		if g.IsActive && g.remainingBullets() != bullets {
+			t.Fatalf("the house changed the bullets from %d to %d", bullets, g.remainingBullets())
+		}
+	}
+	if rate := 100 * float64(cheats) / games; rate < 22 || rate > 28 {
+		t.Errorf("the house cheated on %.1f%% of pulls, want about %d%%", rate, cfg.HouseEdge)
+	}
+}
+
+func TestHouseEdgeDisclosedAtTheEnd(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.HouseEdge = 100
+	g := runningGame(t, cfg, lastInChamber())
+	g.Pull("alice")
+	g.Pull("alice")
+
+	summary := gameSummary(g, time.UTC)
+	if !strings.Contains(summary, "The house cheated: the bullets were secretly moved 1 time(s)!") {
+		t.Errorf("summary doesn't reveal the cheat:\n%s", summary)
+	}
+	if rules := renderRules(cfg); !strings.Contains(rules, "The house cheats") {
+		t.Errorf("rules don't disclose the house edge:\n%s", rules)
+	}
+}
+
+func TestConfirmPull(t *testing.T) {
+	tests := []struct {
+		name    string
+		confirm int
+		pulls   int // Survived pulls before, raising the odds
+		calls   []bool
+		want    []error
+	}{
+		{"off", 0, 4, []bool{false}, []error{nil}},
+		{"below threshold", 50, 0, []bool{false}, []error{nil}},
+		{"at threshold", 50, 4, []bool{false, true}, []error{ErrNeedsConfirm, nil}},
+		{"confirm without warning", 50, 4, []bool{true, true}, []error{ErrNeedsConfirm, nil}},
+		{"unconfirmed again", 50, 4, []bool{false, false}, []error{ErrNeedsConfirm, ErrNeedsConfirm}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.ConfirmOdds = tt.confirm
+			g := runningGame(t, cfg, lastInChamber())
+			for i := 0; i < tt.pulls; i++ {
+				if _, err := g.Pull("alice"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for i, confirmed := range tt.calls {
+				if err := g.ConfirmPull("alice", confirmed); !errors.Is(err, tt.want[i]) {
+					t.Errorf("ConfirmPull(%t) #%d at %.1f%% = %v, want %v", confirmed, i+1, g.NextOdds(), err, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConfirmPullWarningEndsWithTheTurn(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.ConfirmOdds = 20
+	g := runningGame(t, cfg, lastInChamber())
+	g.Pull("alice")
+
+	if err := g.ConfirmPull("alice", false); !errors.Is(err, ErrNeedsConfirm) {
+		t.Fatalf("ConfirmPull() = %v, want %v", err, ErrNeedsConfirm)
+	}
+	g.Pass("alice")
+	// bob wasn't warned, so his confirmation doesn't count yet
+	if err := g.ConfirmPull("bob", true); !errors.Is(err, ErrNeedsConfirm) {
+		t.Errorf("bob's ConfirmPull(true) = %v, want %v", err, ErrNeedsConfirm)
+	}
+	if err := g.ConfirmPull("alice", true); !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("alice's ConfirmPull() after passing = %v, want %v", err, ErrNotYourTurn)
+	}
+}
+
+func TestGameErrors(t *testing.T) {
+	running := func(t *testing.T) *Game { return runningGame(t, defaultConfig(), lastInChamber()) }
+	lobby := func(t *testing.T) *Game { return lobbyGame(t, defaultConfig(), lastInChamber()) }
+
+	tests := []struct {
+		name string
+		game func(t *testing.T) *Game
+		op   func(g *Game) error
+		want error
+	}{
+		{"pull out of turn", running, func(g *Game) error { _, err := g.Pull("bob"); return err }, ErrNotYourTurn},
+		{"pull in the lobby", lobby, func(g *Game) error { _, err := g.Pull("alice"); return err }, ErrGameNotStarted},
+		{"pull after the end", running, func(g *Game) error {
+			g.IsActive = false
+			_, err := g.Pull("alice")
+			return err
+		}, ErrGameOver},
+		{"skip out of turn", running, func(g *Game) error { _, err := g.Skip("carol"); return err }, ErrNotYourTurn},
+		{"skip after pulling", running, func(g *Game) error {
+			g.Pull("alice")
+			_, err := g.Skip("alice")
+			return err
+		}, ErrAlreadyPulled},
+		{"skip without skips", running, func(g *Game) error {
+			g.Skips["alice"] = 0
+			_, err := g.Skip("alice")
+			return err
+		}, ErrNoSkips},
+		{"pass before pulling", running, func(g *Game) error { return g.Pass("alice") }, ErrMustPullFirst},
+		{"pass out of turn", running, func(g *Game) error { return g.Pass("bob") }, ErrNotYourTurn},
+		{"join twice", lobby, func(g *Game) error { return g.Join("bob", "Bob") }, ErrAlreadyJoined},
+		{"join a running game", running, func(g *Game) error { return g.Join("dave", "Dave") }, ErrGameAlreadyStarted},
+		{"start alone", func(t *testing.T) *Game {
+			return newGame("alice", "Alice", defaultConfig(), lastInChamber())
+		}, func(g *Game) error { return g.Start() }, ErrNotEnoughPlayers},
+		{"start twice", running, func(g *Game) error { return g.Start() }, ErrGameAlreadyStarted},
+		{"leave without joining", lobby, func(g *Game) error { return g.Leave("dave") }, ErrNotInGame},
+		{"restart in the lobby", lobby, func(g *Game) error { return g.Restart() }, ErrGameNotStarted},
+		{"respin after pulling", running, func(g *Game) error {
+			g.Pull("alice")
+			return g.Respin("alice")
+		}, ErrAlreadyPulled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.game(t)
+			if err := tt.op(g); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeave(t *testing.T) {
+	g := lobbyGame(t, defaultConfig(), lastInChamber())
+	g.AssistConsent["bob"] = true
+	if err := g.Leave("bob"); err != nil {
+		t.Fatalf("Leave(bob) = %v", err)
+	}
+	if g.HasPlayer("bob") || strings.Join(g.Players, ",") != "alice,carol" {
+		t.Errorf("players after bob left = %v", g.Players)
+	}
+	if _, ok := g.Skips["bob"]; ok {
+		t.Error("bob kept his skips")
+	}
+	if g.AssistConsent["bob"] {
+		t.Error("bob kept his assistance consent")
+	}
+	if err := g.Leave("bob"); !errors.Is(err, ErrNotInGame) {
+		t.Errorf("second Leave(bob) = %v, want %v", err, ErrNotInGame)
+	}
+
+	running := runningGame(t, defaultConfig(), lastInChamber())
+	if err := running.Leave("alice"); !errors.Is(err, ErrGameAlreadyStarted) {
+		t.Errorf("Leave() from a running game = %v, want %v", err, ErrGameAlreadyStarted)
+	}
+	if !running.HasPlayer("alice") || running.CurrentPlayer() != "alice" {
+		t.Errorf("a refused leave changed the roster to %v", running.Players)
+	}
+}
+
+func TestSetReload(t *testing.T) {
+	tests := []struct {
+		payload string
+		want    ReloadSettings
+		wantErr error
+	}{
+		{"chambers=8", ReloadSettings{Chambers: 8, Bullets: 1}, nil},
+		{"bullets=2", ReloadSettings{Chambers: 6, Bullets: 2}, nil},
+		{"Chambers=4 bullets=3", ReloadSettings{Chambers: 4, Bullets: 3}, nil},
+		{"", ReloadSettings{Chambers: 6, Bullets: 1}, nil},
+		{"chambers=1", ReloadSettings{}, ErrInvalidOption},
+		{"bullets=7", ReloadSettings{}, ErrInvalidOption},
+		{"mode=hardcore", ReloadSettings{}, ErrInvalidOption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.payload, func(t *testing.T) {
+			g := runningGame(t, defaultConfig(), lastInChamber())
+			got, err := g.SetReload(tt.payload)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) || got != tt.want {
+				t.Fatalf("SetReload(%q) = %+v, %v, want %+v, %v", tt.payload, got, err, tt.want, tt.wantErr)
+			}
+			if tt.wantErr != nil && g.PendingReload != nil {
+				t.Errorf("a refused reload is pending: %+v", *g.PendingReload)
+			}
+		})
+	}
+
+	lobby := lobbyGame(t, defaultConfig(), lastInChamber())
+	if _, err := lobby.SetReload("chambers=8"); !errors.Is(err, ErrGameNotStarted) {
+		t.Errorf("SetReload() in the lobby = %v, want %v", err, ErrGameNotStarted)
+	}
+}
+
+func TestPendingReloadWaitsForTheNextReload(t *testing.T) {
+	g := runningGame(t, defaultConfig(), lastInChamber())
+	if _, err := g.SetReload("chambers=4"); err != nil {
+		t.Fatalf("SetReload(chambers=4) = %v", err)
+	}
+	if _, err := g.SetReload("bullets=2"); err != nil {
+		t.Fatalf("SetReload(bullets=2) = %v", err)
+	}
+
+	// The current cylinder is played out unchanged
+	if _, err := g.Pull("alice"); err != nil {
+		t.Fatalf("Pull(alice) = %v", err)
+	}
+	if g.Chambers != defaultChambers || len(g.Cylinder) != defaultChambers || g.Bullets != 1 {
+		t.Fatalf("cylinder changed before the reload: %d chambers, %d bullet(s)", len(g.Cylinder), g.Bullets)
+	}
+
+	// A respin keeps the cylinder's size and leaves the settings pending
+	g.Pass("alice")
+	if err := g.Respin("bob"); err != nil {
+		t.Fatalf("Respin(bob) = %v", err)
+	}
+	if g.Chambers != defaultChambers || g.PendingReload == nil {
+		t.Fatalf("respin applied the pending reload: %d chambers, pending %v", g.Chambers, g.PendingReload)
+	}
+	if err := g.Respin("bob"); !errors.Is(err, ErrNoReloads) {
+		t.Errorf("second Respin(bob) = %v, want %v", err, ErrNoReloads)
+	}
+
+	g.reload()
+	if g.Chambers != 4 || len(g.Cylinder) != 4 || g.Bullets != 2 || g.remainingBullets() != 2 {
+		t.Errorf("after the reload: %d chambers, cylinder %v, %d bullet(s), want 4 chambers with 2", g.Chambers, g.Cylinder, g.Bullets)
+	}
+	if g.PendingReload != nil {
+		t.Errorf("reload left %+v pending", *g.PendingReload)
+	}
+}
+
+func TestTurnActions(t *testing.T) {
+	// A bullet in the last of 6 chambers, so 5 pulls survive
+	g := runningGame(t, defaultConfig(), lastInChamber())
+
+	steps := []struct {
+		action     string
+		player     string
+		wantTurn   string
+		wantPulls  int
+		wantOdds   float64 // Odds of the next pull after the action
+		wantSkips  int     // The acting player's skips left
+		wantPulled bool
+	}{
+		{"pull", "alice", "alice", 1, 20, defaultSkips, true},
+		{"pull", "alice", "alice", 2, 25, defaultSkips, true},
+		{"pass", "alice", "bob", 2, 25, defaultSkips, false},
+		{"skip", "bob", "carol", 2, 25, defaultSkips - 1, false},
+		{"pull", "carol", "carol", 3, 100.0 / 3, defaultSkips, true},
+		{"pass", "carol", "alice", 3, 100.0 / 3, defaultSkips, false},
+	}
+	for i, step := range steps {
+		var err error
+		switch step.action {
+		case "pull":
+			var result PullResult
+			result, err = g.Pull(step.player)
+			if err == nil && (result.Dead || result.Chamber != step.wantPulls || result.Odds != step.wantOdds) {
+				t.Errorf("step %d: Pull(%s) = %+v", i+1, step.player, result)
+			}
+		case "pass":
+			err = g.Pass(step.player)
+		case "skip":
+			_, err = g.Skip(step.player)
+		}
+		if err != nil {
+			t.Fatalf("step %d: %s by %s = %v", i+1, step.action, step.player, err)
+		}
+		if g.CurrentPlayer() != step.wantTurn || g.PullCount != step.wantPulls || g.HasPulledOnTurn != step.wantPulled {
+			t.Errorf("step %d: %s's turn after %d pull(s), pulled %t, want %s's after %d, pulled %t",
+				i+1, g.CurrentPlayer(), g.PullCount, g.HasPulledOnTurn, step.wantTurn, step.wantPulls, step.wantPulled)
+		}
+		if g.NextOdds() != step.wantOdds || g.Skips[step.player] != step.wantSkips {
+			t.Errorf("step %d: next odds %.1f%%, %s has %d skip(s), want %.1f%% and %d",
+				i+1, g.NextOdds(), step.player, g.Skips[step.player], step.wantOdds, step.wantSkips)
+		}
+	}
+	if !g.FullRound {
+		t.Error("every player has had a turn, but the round isn't full")
+	}
+}
+
+func TestRespin(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(g *Game)
+		player  string
+		wantErr error
+	}{
+		{"after the last turn's pulls", func(g *Game) {
+			g.Pull("alice")
+			g.Pass("alice")
+		}, "bob", nil},
+		{"out of turn", func(g *Game) {}, "bob", ErrNotYourTurn},
+		{"after pulling", func(g *Game) { g.Pull("alice") }, "alice", ErrAlreadyPulled},
+		{"out of reloads", func(g *Game) { g.Reloads = maxReloads }, "alice", ErrNoReloads},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The respun cylinder gets its bullet in the first chamber
+			g := runningGame(t, defaultConfig(), &scriptedRandomizer{values: []int{defaultChambers - 1, 0}})
+			tt.setup(g)
+			pulls, reloads := g.PullCount, g.Reloads
+
+			err := g.Respin(tt.player)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("Respin(%s) = %v, want %v", tt.player, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if g.PullCount != pulls || g.Reloads != reloads {
+					t.Errorf("a refused respin left %d pull(s) and %d reload(s), want %d and %d", g.PullCount, g.Reloads, pulls, reloads)
+				}
+				return
+			}
+			if g.PullCount != 0 || g.Reloads != reloads+1 || !g.Cylinder[0] || g.remainingBullets() != 1 {
+				t.Errorf("respin left %d pull(s), %d reload(s) and cylinder %v", g.PullCount, g.Reloads, g.Cylinder)
+			}
+			if g.CurrentPlayer() != tt.player || g.NextOdds() != 100.0/defaultChambers {
+				t.Errorf("after the respin %s is up at %.1f%%, want %s at the odds of a fresh cylinder", g.CurrentPlayer(), g.NextOdds(), tt.player)
+			}
+		})
+	}
+}
+
+func TestRestartThenChangeMode(t *testing.T) {
+	tests := []struct {
+		from, to Mode
+		check    func(g *Game) bool
+	}{
+		{ModeSpin, ModeClassic, func(g *Game) bool { return !g.SpinEach }},
+		{ModeHardcore, ModeClassic, func(g *Game) bool { return g.SkipsPerPlayer == defaultSkips && g.Skips["bob"] == defaultSkips }},
+		{ModeElim, ModeClassic, func(g *Game) bool { return !g.Elimination }},
+		{ModeClassic, ModeSpin, func(g *Game) bool { return g.SpinEach }},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s to %s", tt.from, tt.to), func(t *testing.T) {
+			g := lobbyGame(t, defaultConfig(), lastInChamber())
+			g.SetMode(tt.from)
+			if err := g.Start(); err != nil {
+				t.Fatalf("Start() = %v", err)
+			}
+			if err := g.Restart(); err != nil {
+				t.Fatalf("Restart() = %v", err)
+			}
+			if g.Mode != tt.from || g.GameConfig != g.StartConfig {
+				t.Errorf("restarted lobby has settings %+v, want the ones it started with %+v", g.GameConfig, g.StartConfig)
+			}
+			if err := g.SetMode(tt.to); err != nil {
+				t.Fatalf("SetMode(%s) = %v", tt.to, err)
+			}
+			if err := g.Start(); err != nil {
+				t.Fatalf("second Start() = %v", err)
+			}
+			if g.Mode != tt.to || !tt.check(g) {
+				t.Errorf("game restarted in %s mode plays with %+v", tt.to, g.GameConfig)
+			}
+		})
+	}
+}
+
+func TestRestartDropsChangesMadeDuringPlay(t *testing.T) {
+	g := runningGame(t, defaultConfig(), lastInChamber())
+	// What /voteharder, /reload and /kick leave behind
+	g.Bullets = 2
+	g.PendingBullets = 1
+	g.PendingReload = &ReloadSettings{Chambers: 8, Bullets: 3}
+	g.HarderVotes["dave"] = true
+	g.Kicked = map[string]bool{"erin": true}
+
+	if err := g.Restart(); err != nil {
+		t.Fatalf("Restart() = %v", err)
+	}
+	if g.Bullets != 1 || g.Chambers != defaultChambers || len(g.Cylinder) != defaultChambers || g.remainingBullets() != 1 {
+		t.Errorf("restarted with %d bullet(s) in %d chambers, want 1 in %d", g.remainingBullets(), len(g.Cylinder), defaultChambers)
+	}
+	if g.PendingBullets != 0 || g.PendingReload != nil || len(g.HarderVotes) != 0 || len(g.Kicked) != 0 {
+		t.Errorf("restart kept %d pending bullet(s), reload %v, votes %v and kicks %v", g.PendingBullets, g.PendingReload, g.HarderVotes, g.Kicked)
+	}
+	if err := g.Join("erin", "Erin"); err != nil {
+		t.Errorf("Join() of a player kicked before the restart = %v", err)
+	}
+}
+
+func TestTurnIndexStaysInRange(t *testing.T) {
+	g := runningGame(t, defaultConfig(), lastInChamber())
+	order := []string{"alice", "bob", "carol"}
+
+	for turn := 0; turn < 100000; turn++ {
+		if g.CurrentPos < 0 || g.CurrentPos >= len(g.Players) {
+			t.Fatalf("turn %d: CurrentPos = %d with %d players", turn, g.CurrentPos, len(g.Players))
+		}
+		if want := order[turn%len(order)]; g.CurrentPlayer() != want {
+			t.Fatalf("turn %d is %s's, want %s's", turn, g.CurrentPlayer(), want)
+		}
+		g.advance()
+	}
+	if !g.FullRound {
+		t.Error("FullRound isn't set after the turns came round")
+	}
+}
+
+func TestTurnIndexAfterRosterChanges(t *testing.T) {
+	tests := []struct {
+		name       string
+		turn       string // Whose turn it is when the player is kicked
+		kick       string
+		wantPlayer string
+	}{
+		{"before the current player", "carol", "alice", "carol"},
+		{"the current player", "bob", "bob", "carol"},
+		{"the last seat on their turn", "dave", "dave", "alice"},
+		{"after the current player", "alice", "carol", "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := lobbyGame(t, defaultConfig(), lastInChamber())
+			if err := g.Join("dave", "Dave"); err != nil {
+				t.Fatal(err)
+			}
+			if err := g.Start(); err != nil {
+				t.Fatal(err)
+			}
+			for g.CurrentPlayer() != tt.turn {
+				g.advance()
+			}
+
+			if _, err := g.Kick(tt.kick); err != nil {
+				t.Fatalf("Kick(%s) = %v", tt.kick, err)
+			}
+			if g.CurrentPos < 0 || g.CurrentPos >= len(g.Players) {
+				t.Fatalf("CurrentPos = %d with %d players", g.CurrentPos, len(g.Players))
+			}
+			if g.CurrentPlayer() != tt.wantPlayer {
+				t.Errorf("turn passed to %s, want %s", g.CurrentPlayer(), tt.wantPlayer)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+const defaultGamesFile = "data/games.json"
+
+// gameSaveInterval is how often the games in play are written to disk, if
+// anything about them changed
+const gameSaveInterval = 5 * time.Second
+
+// gameStore persists the games of every chat so a restart doesn't lose the
+// games in play. It saves the games as the chats last published them, so it
+// never has to lock a chat.
+type gameStore struct {
+	mu   sync.Mutex
+	path string
+	last []byte // What was last written, to skip writes that change nothing
+}
+
+// loadGames reads the games saved at path into the chats' rooms. A missing
+// file yields no games, and so does a corrupt one, after reporting the error.
+// It runs before any update is handled.
+func loadGames(path string) (*gameStore, error) {
+	s := &gameStore{path: path}
+	saved := make(map[int64]*Game)
+	if err := readJSONFile(path, &saved); err != nil {
+		return s, err
+	}
+
+	for chatID, game := range saved {
+		if game == nil || !game.IsActive {
+			continue
+		}
+		restoreGame(game)
+		r := lockChat(chatID)
+		r.game = game
+		r.unlock()
+		if game.Generation > lastGeneration.Load() {
+			lastGeneration.Store(game.Generation)
+		}
+	}
+	return s, nil
+}
+
+// restoreGame fills in what a saved game doesn't keep. The game continues on a
+// fresh rng, so its seed only reproduces it up to the restart.
+func restoreGame(game *Game) {
+	game.rng = newRandomizer()
+	for _, m := range []*map[string]bool{&game.HarderVotes, &game.AssistConsent} {
+		if *m == nil {
+			*m = make(map[string]bool)
+		}
+	}
+	if game.Names == nil {
+		game.Names = make(map[string]string)
+	}
+	if game.Skips == nil {
+		game.Skips = make(map[string]int)
+	}
+	if game.Bots == nil {
+		game.Bots = make(map[string]BotPlayer)
+	}
+}
+
+// save writes the active games to disk if they changed since the last save
+func (s *gameStore) save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	active := make(map[int64]json.RawMessage)
+	for chatID, info := range publishedGames() {
+		if info.saved != nil {
+			active[chatID] = info.saved
+		}
+	}
+	data, err := json.Marshal(active)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, s.last) {
+		return nil
+	}
+	if err := writeJSONFile(s.path, active); err != nil {
+		return err
+	}
+	s.last = data
+	return nil
+}
+
+// savingAfter makes a handler save the games as soon as it is done, so a
+// command's effect survives a crash right after it. Changes made by timers
+// are saved by saveEvery.
+func (s *gameStore) savingAfter(handler func(*telebot.Message)) func(*telebot.Message) {
+	return func(m *telebot.Message) {
+		handler(m)
+
+		if err := s.save(); err != nil {
+			log.Printf("Error saving games: %v", err)
+		}
+	}
+}
+
+// saveEvery saves the games every interval for as long as the bot runs
+func (s *gameStore) saveEvery(interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := s.save(); err != nil {
+			log.Printf("Error saving games: %v", err)
+		}
+	}
+}
+
+// resumeGames restarts the timers of the games loaded at startup and tells
+// each chat its game survived the restart, and whose move it is
+func resumeGames(s Sender) {
+	for chatID := range publishedGames() {
+		resumeGame(s, &telebot.Chat{ID: chatID})
+	}
+}
+
+// resumeGame restarts the timers of the chat's recovered game and tells the
+// chat about it
+func resumeGame(s Sender, chat *telebot.Chat) {
+	r := lockChat(chat.ID)
+	defer r.unlock()
+
+	game := r.game
+	if game == nil || !game.IsActive {
+		return
+	}
+	if game.Phase == PhaseLobby {
+		senderFor(s, game).Send(chat, fmt.Sprintf("♻️ I'm back! The game waiting for players was recovered. Players so far: %s\nUse /join to join or /start to begin.",
+			game.nameList(game.Players)))
+		scheduleJoinWindow(s, chat, game)
+		return
+	}
+
+	game.badges = stats.badges(chat.ID)
+	senderFor(s, game).Send(chat, fmt.Sprintf("♻️ I'm back! The game was recovered, it's %s's turn.", game.name(game.CurrentPlayer())), turnOptions(game)...)
+	afterAction(s, chat, game)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/tucnak/telebot"
+)
+
+func TestRecoveredGamesAreAnnounced(t *testing.T) {
+	chat := testChat(t)
+	lobbyChat := &telebot.Chat{ID: chat.ID - 1, Type: telebot.ChatGroup}
+	s := &recordingSender{}
+
+	r := lockChat(chat.ID)
+	game := playChat(t, chat, defaultConfig(), lastInChamber())
+	playPull(s, chat, game, "alice")
+	playPass(s, chat, game, "alice")
+	r.unlock()
+	r = lockChat(lobbyChat.ID)
+	r.game = lobbyGame(t, defaultConfig(), lastInChamber())
+	r.unlock()
+
+	path := filepath.Join(t.TempDir(), "games.json")
+	if err := (&gameStore{path: path}).save(); err != nil {
+		t.Fatalf("save() = %v", err)
+	}
+
+	// The restart: the timers and rooms are gone until the games are loaded
+	game.stopTimers()
+	roomsMu.Lock()
+	rooms = make(map[int64]*room)
+	roomsMu.Unlock()
+	if _, err := loadGames(path); err != nil {
+		t.Fatalf("loadGames() = %v", err)
+	}
+	s = &recordingSender{}
+	resumeGames(s)
+
+	if got := s.sentWith("♻️ I'm back! The game was recovered, it's bob's turn."); len(got) != 1 {
+		t.Errorf("running game recovered with %q", s.sent)
+	}
+	if got := s.sentWith("The game waiting for players was recovered. Players so far: Alice, bob, carol"); len(got) != 1 {
+		t.Errorf("lobby recovered with %q", s.sent)
+	}
+	if len(s.sent) != 2 {
+		t.Errorf("sent %d message(s), want one per recovered game: %q", len(s.sent), s.sent)
+	}
+
+	r = lockChat(chat.ID)
+	defer r.unlock()
+	restored := r.game
+	if restored == nil || restored.CurrentPlayer() != "bob" || restored.PullCount != 1 {
+		t.Fatalf("restored game = %+v, want bob's turn after 1 pull", restored)
+	}
+	if restored.turnTimer == nil {
+		t.Error("the recovered game's turn timer wasn't restarted")
+	}
+}
@@ -3,8 +3,11 @@ module telegram-roulette
 go 1.23.2
 
 require (
-	github.com/joho/godotenv v1.5.1 // indirect
+	github.com/joho/godotenv v1.5.1
+	github.com/tucnak/telebot v2.0.0+incompatible
+)
+
+require (
 	github.com/mitchellh/hashstructure v1.1.0 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/tucnak/telebot v2.0.0+incompatible // indirect
 )
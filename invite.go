@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tucnak/telebot"
+)
+
+// invitePrefix starts the /start payload of an invite deep link
+const invitePrefix = "join_"
+
+// errBadInvite is returned for a /start payload that isn't an invite
+var errBadInvite = errors.New("not an invite")
+
+// inviteLink returns a t.me link that opens a private chat with the bot and
+// joins the game of the chat when followed. Telegram only allows letters,
+// digits, _ and - in the payload.
+func inviteLink(botUsername string, chatID int64, generation uint64) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s", botUsername, encodeInvite(chatID, generation))
+}
+
+func encodeInvite(chatID int64, generation uint64) string {
+	return invitePrefix + strconv.FormatInt(chatID, 36) + "_" + strconv.FormatUint(generation, 36)
+}
+
+// decodeInvite reads the chat and game generation back out of an invite payload
+func decodeInvite(payload string) (chatID int64, generation uint64, err error) {
+	rest, ok := strings.CutPrefix(payload, invitePrefix)
+	if !ok {
+		return 0, 0, errBadInvite
+	}
+	chat, gen, ok := strings.Cut(rest, "_")
+	if !ok {
+		return 0, 0, errBadInvite
+	}
+	if chatID, err = strconv.ParseInt(chat, 36, 64); err != nil {
+		return 0, 0, errBadInvite
+	}
+	if generation, err = strconv.ParseUint(gen, 36, 64); err != nil {
+		return 0, 0, errBadInvite
+	}
+	return chatID, generation, nil
+}
+
+// joinByInvite joins the sender of a /start deep link to the invited game. It
+// locks the group the game is in, so the caller must not have any chat locked.
+func joinByInvite(s Sender, m *telebot.Message) {
+	chatID, generation, err := decodeInvite(m.Payload)
+	if err != nil {
+		s.Send(m.Chat, "That invite link isn't valid!")
+		return
+	}
+	defer lockChat(chatID).unlock()
+
+	game, err := liveGame(chatID, generation)
+	if err != nil {
+		s.Send(m.Chat, "That invite has expired, the game is over or has moved on.")
+		return
+	}
+
+	playerID := getPlayerID(m.Sender)
+	if err := game.Join(playerID, displayName(m.Sender)); err != nil {
+		s.Send(m.Chat, errorMessage(err, game))
+		return
+	}
+
+	s.Send(m.Chat, "✅ You joined the game! Head back to the group to play.")
+	announceJoin(s, &telebot.Chat{ID: chatID}, game, playerID, " through an invite")
+}
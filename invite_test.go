@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/tucnak/telebot"
+)
+
+func TestInviteRoundTrip(t *testing.T) {
+	// Telegram only allows these in a /start payload, at most 64 of them
+	allowed := regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+	tests := []struct {
+		chatID     int64
+		generation uint64
+	}{
+		{-1001234567890, 1},
+		{-42, 987654321},
+		{123456789, 0},
+		{-9223372036854775808, 18446744073709551615},
+	}
+	for _, tt := range tests {
+		payload := encodeInvite(tt.chatID, tt.generation)
+		if !allowed.MatchString(payload) {
+			t.Errorf("encodeInvite(%d, %d) = %q, which Telegram doesn't accept", tt.chatID, tt.generation, payload)
+		}
+		chatID, generation, err := decodeInvite(payload)
+		if err != nil || chatID != tt.chatID || generation != tt.generation {
+			t.Errorf("decodeInvite(%q) = %d, %d, %v, want %d, %d", payload, chatID, generation, err, tt.chatID, tt.generation)
+		}
+	}
+}
+
+func TestDecodeInviteRejects(t *testing.T) {
+	for _, payload := range []string{
+		"",
+		"hello",
+		"join_",
+		"join_abc",
+		"join_abc_",
+		"join__1",
+		"join_!!_1",
+		"join_abc_-1",
+		"invite_abc_1",
+	} {
+		if _, _, err := decodeInvite(payload); !errors.Is(err, errBadInvite) {
+			t.Errorf("decodeInvite(%q) = %v, want %v", payload, err, errBadInvite)
+		}
+	}
+}
+
+func TestJoinByInvite(t *testing.T) {
+	group := testChat(t)
+	game := lobbyGame(t, defaultConfig(), lastInChamber())
+	game.Generation = nextGeneration()
+	roomOf(group.ID).game = game
+	private := &telebot.Chat{ID: 55, Type: telebot.ChatPrivate}
+	dave := &telebot.User{ID: 55, Username: "dave"}
+
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+		joined  bool
+	}{
+		{"bad link", "join_nonsense", "That invite link isn't valid!", false},
+		{"old game", encodeInvite(group.ID, game.Generation-1), "That invite has expired", false},
+		{"other chat", encodeInvite(group.ID-1, game.Generation), "That invite has expired", false},
+		{"valid", encodeInvite(group.ID, game.Generation), "✅ You joined the game!", true},
+		{"again", encodeInvite(group.ID, game.Generation), "You're already in the game!", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &recordingSender{}
+			joinByInvite(s, &telebot.Message{Chat: private, Sender: dave, Payload: tt.payload})
+			if len(s.sent) == 0 || len(s.sentWith(tt.want)) == 0 {
+				t.Errorf("replies = %q, want %q", s.sent, tt.want)
+			}
+			if game.HasPlayer("55") != tt.joined {
+				t.Errorf("dave in the game = %t, want %t", game.HasPlayer("55"), tt.joined)
+			}
+		})
+	}
+}
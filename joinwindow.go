@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+const (
+	maxJoinWindow       = 3600 // seconds
+	defaultJoinReminder = 10   // seconds before the join window closes
+)
+
+// scheduleJoinWindow closes the lobby JoinWindow seconds after the game was
+// created, starting the game if enough players joined and cancelling it
+// otherwise, and reminds the chat JoinReminder seconds before that. Both
+// timers are stopped when the game starts early.
+func scheduleJoinWindow(s Sender, chat *telebot.Chat, game *Game) {
+	if game.JoinWindow == 0 {
+		return
+	}
+	window := time.Duration(game.JoinWindow) * time.Second
+	generation := game.Generation
+
+	// inLobby returns the game if it is still the same one waiting for players.
+	// The caller has the chat locked.
+	inLobby := func() (*Game, bool) {
+		game, err := liveGame(chat.ID, generation)
+		return game, err == nil && game.Phase == PhaseLobby
+	}
+
+	if reminder := time.Duration(game.JoinReminder) * time.Second; reminder > 0 && reminder < window {
+		game.joinTimers = append(game.joinTimers, time.AfterFunc(window-reminder, func() {
+			defer lockChat(chat.ID).unlock()
+
+			if game, ok := inLobby(); ok {
+				senderFor(s, game).Send(chat, fmt.Sprintf("⏳ Joining closes in %d second(s)! Use /join now. Players so far: %s",
+					game.JoinReminder, game.nameList(game.Players)))
+			}
+		}))
+	}
+
+	game.joinTimers = append(game.joinTimers, time.AfterFunc(window, func() {
+		r := lockChat(chat.ID)
+		defer r.unlock()
+
+		game, ok := inLobby()
+		if !ok {
+			return
+		}
+		if len(game.Players) < minPlayers {
+			r.game = nil
+			senderFor(s, game).Send(chat, fmt.Sprintf("⌛ Joining closed with fewer than %d players, so the game is cancelled.", minPlayers))
+			startQueuedGame(s, chat)
+			return
+		}
+		senderFor(s, game).Send(chat, "⌛ Joining closed!")
+		startGame(s, chat, game)
+	}))
+}
+
+// stopJoinWindow cancels the join window timers of a game that is starting
+func stopJoinWindow(game *Game) {
+	for _, timer := range game.joinTimers {
+		timer.Stop()
+	}
+	game.joinTimers = nil
+}
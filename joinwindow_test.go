@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJoinReminder(t *testing.T) {
+	tests := []struct {
+		name         string
+		reminder     int
+		startEarly   bool
+		wantTimers   int
+		wantReminder bool
+	}{
+		{"reminds before closing", 1, false, 2, true},
+		{"cancelled by an early start", 1, true, 2, false},
+		{"no reminder configured", 0, false, 1, false},
+		{"reminder as long as the window", 2, false, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := testChat(t)
+			cfg := defaultConfig()
+			cfg.JoinWindow, cfg.JoinReminder = 2, tt.reminder
+			s := &recordingSender{}
+
+			r := lockChat(chat.ID)
+			game := createGame(chat, "alice", "Alice", cfg)
+			game.Join("bob", "Bob")
+			scheduleJoinWindow(s, chat, game)
+			if len(game.joinTimers) != tt.wantTimers {
+				t.Errorf("scheduled %d timer(s), want %d", len(game.joinTimers), tt.wantTimers)
+			}
+			if tt.startEarly {
+				startGame(s, chat, game)
+			}
+			r.unlock()
+			if tt.wantTimers < 2 {
+				// Only the closing timer, so there is no reminder to wait for
+				return
+			}
+
+			// The reminder is due a second in, the window closes a second later
+			time.Sleep(1300 * time.Millisecond)
+
+			r = lockChat(chat.ID)
+			defer r.unlock()
+			reminders := s.sentWith("Joining closes in 1 second(s)!")
+			if got := len(reminders) == 1; got != tt.wantReminder {
+				t.Errorf("reminded = %t, want %t: %q", got, tt.wantReminder, s.sent)
+			}
+			if !tt.startEarly && game.Phase != PhaseLobby {
+				t.Errorf("the lobby closed before its window ran out")
+			}
+			if len(s.sentWith("Joining closed")) != 0 {
+				t.Errorf("the window closed early: %q", s.sent)
+			}
+		})
+	}
+}
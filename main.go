@@ -1,285 +1,1146 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"math/rand"
 	"os"
-	"sync"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/joho/godotenv"
 	"github.com/tucnak/telebot"
 )
 
-// getPlayerID returns a suitable identifier for the player
+// getPlayerID returns a suitable identifier for the player. It is their
+// Telegram ID, which stays the same when they change their name.
 func getPlayerID(sender *telebot.User) string {
-	if sender.Username != "" {
-		return sender.Username
-	}
-	if sender.FirstName != "" {
-		return sender.FirstName
-	}
-	return fmt.Sprintf("player%d", sender.ID)
+	return strconv.Itoa(sender.ID)
 }
 
-type Game struct {
-	Players         []string
-	Bullet          int
-	CurrentPos      int
-	PullCount       int
-	IsActive        bool
-	Skips           map[string]int // Track remaining skips for each player
-	HasPulledOnTurn bool           // Track if current player has pulled at least once on their turn
+// errorMessage turns an error from a Game method into a reply for the chat
+func errorMessage(err error, game *Game) string {
+	switch {
+	case errors.Is(err, ErrNotYourTurn):
+		return fmt.Sprintf("It's not your turn! Waiting for %s to play.", game.name(game.CurrentPlayer()))
+	case errors.Is(err, ErrAlreadyJoined):
+		return "You're already in the game!"
+	case errors.Is(err, ErrNotInGame):
+		return "You're not in the game!"
+	case errors.Is(err, ErrAlreadyPulled):
+		return "You've already pulled the trigger this turn! Use /pass to end your turn."
+	case errors.Is(err, ErrMustPullFirst):
+		return "You must pull the trigger at least once before passing!"
+	case errors.Is(err, ErrNoSkips):
+		return "You have no skips remaining! You must /pull!"
+	case errors.Is(err, ErrNotEnoughPlayers):
+		return fmt.Sprintf("Need at least %d players to start!", minPlayers)
+	case errors.Is(err, ErrGameNotStarted):
+		return "The game hasn't started yet! Use /start when all players have joined."
+	case errors.Is(err, ErrGameAlreadyStarted):
+		return "The game has already started!"
+	case errors.Is(err, ErrGameOver), errors.Is(err, ErrNoActiveGame):
+		return "No active game! Use /create to create a new game."
+	case errors.Is(err, ErrGameJustEnded):
+		return "The game just ended! Use /replay to see how it went, or /create to start a new one."
+	case errors.Is(err, ErrUnknownOption):
+		return fmt.Sprintf("Sorry, %v.\nAvailable options:%s", err, createOptionsHelp)
+	case errors.Is(err, ErrUnknownStrategy):
+		return "Unknown bot strategy! Available strategies:" + botStrategyHelp
+	case errors.Is(err, ErrInvalidOption):
+		return fmt.Sprintf("Sorry, %v.", err)
+	case errors.Is(err, ErrNotSpectator):
+		return "Only spectators can vote! Players have enough to worry about."
+	case errors.Is(err, ErrAlreadyVoted):
+		return "You've already voted!"
+	case errors.Is(err, ErrNoTurnTimer):
+		return "This game has no turn timer!"
+	case errors.Is(err, ErrAlreadyExtended):
+		return "The timer was already extended this turn!"
+	case errors.Is(err, ErrNeedsConfirm):
+		return fmt.Sprintf("⚠️ Careful! The next pull has a %.1f%% chance of being fatal.\nUse /pull confirm if you really want to pull.", game.NextOdds())
+	case errors.Is(err, ErrKicked):
+		return "You were kicked from this game!"
+	case errors.Is(err, ErrCylinderFull):
+		return "There's no room left in the cylinder for another bullet!"
+	case errors.Is(err, ErrNoReloads):
+		return fmt.Sprintf("The cylinder can only be reloaded %d time(s) per game!", maxReloads)
+	case errors.Is(err, ErrGameFull):
+		return fmt.Sprintf("Game is full (%d/%d players)", len(game.Players), game.MaxPlayers)
+	case errors.Is(err, ErrEliminated):
+		return "You've been eliminated from this game!"
+	case errors.Is(err, ErrNoConsent):
+		return "They have to allow it first by sending /assistme!"
+	case errors.Is(err, ErrStaleGame):
+		return "That game has already ended or been replaced!"
+	case errors.Is(err, ErrStaleTurn):
+		return "Those buttons are from an earlier turn!"
+	case errors.Is(err, ErrUnknownMode):
+		return "Unknown mode! Available modes:" + modeList()
+	default:
+		log.Printf("Unexpected game error: %v", err)
+		return "Something went wrong."
+	}
 }
 
 var (
-	games = make(map[int64]*Game)
-	mutex sync.Mutex
+	stats *statsStore
+	chats *chatStore
 )
 
 func main() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
+	loadDotEnv()
+	loadOwnerID()
+	loadMaxActiveGames()
+	loadTurnTimeout()
+
+	statsFile := envOr("STATS_FILE", defaultStatsFile)
+	chatsFile := envOr("CHATS_FILE", defaultChatsFile)
+	gamesFile := envOr("GAME_STATE_FILE", defaultGamesFile)
+
+	var err error
+	if stats, err = loadStats(statsFile); err != nil {
+		log.Printf("Error loading stats, starting fresh: %v", err)
+	}
+	if chats, err = loadChatConfigs(chatsFile); err != nil {
+		log.Printf("Error loading chat configs, using defaults: %v", err)
+	}
+	gameState, err := loadGames(gamesFile)
+	if err != nil {
+		log.Printf("Error loading games, starting without any: %v", err)
 	}
 
-	token := os.Getenv("TELEGRAM_BOT_TOKEN")
-	if token == "" {
-		log.Fatal("TELEGRAM_BOT_TOKEN environment variable is not set")
+	token, err := secret("TELEGRAM_BOT_TOKEN")
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	bot, err := telebot.NewBot(telebot.Settings{
-		Token:  token,
-		Poller: &telebot.LongPoller{Timeout: 10 * time.Second},
-	})
+	bot, err := telebot.NewBot(botSettings(token))
 
 	if err != nil {
-		log.Fatal(err)
+		log.Fatalf("Error connecting to Telegram, check TELEGRAM_BOT_TOKEN: %v", err)
+	}
+	if err := selfCheck(bot, statsFile, chatsFile, gamesFile); err != nil {
+		log.Fatalf("Startup check failed: %v", err)
+	}
+	sender := newDedupingSender(bot, dedupeWindow(os.Getenv("SEND_DEDUPE_WINDOW")))
+	finishedRetention = parseRetention(os.Getenv("FINISHED_GAME_RETENTION"))
+	wrap := func(handler func(*telebot.Message)) func(*telebot.Message) {
+		return withUnannounced(sender, withFreshName(gameState.savingAfter(handler)))
+	}
+	handle := func(endpoint string, handler func(*telebot.Message)) {
+		wrapped := guard(bot.Me, wrap(handler))
+		bot.Handle(endpoint, wrapped)
+		commands[strings.TrimPrefix(endpoint, "/")] = wrapped
 	}
 
-	bot.Handle("/create", func(m *telebot.Message) {
-		mutex.Lock()
-		defer mutex.Unlock()
+	handle("/create", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
 
-		if game, exists := games[m.Chat.ID]; exists && game.IsActive {
-			bot.Send(m.Chat, "A game is already in progress!")
+		if game := r.game; game != nil && game.IsActive {
+			sender.Send(m.Chat, "A game is already in progress!")
+			return
+		}
+		if refuseNewGame(sender, m.Chat) {
 			return
 		}
 
-		playerID := getPlayerID(m.Sender)
-		log.Printf("New game started by player: %s", playerID)
+		cfg := chats.get(m.Chat.ID).Defaults
+		if err := parseCreateOptions(m.Payload, &cfg); err != nil {
+			sender.Send(m.Chat, errorMessage(err, nil))
+			return
+		}
+
+		game := createGame(m.Chat, getPlayerID(m.Sender), displayName(m.Sender), cfg)
+		scheduleJoinWindow(sender, m.Chat, game)
+
+		s := senderFor(sender, game)
+		if cfg.Host {
+			s.Send(m.Chat, fmt.Sprintf("🎮 %s is hosting a game of Russian Roulette!\nUse /join to join the game.\nUse /start when all players have joined.", displayName(m.Sender)))
+			return
+		}
+		s.Send(m.Chat, fmt.Sprintf("🎮 %s started a game of Russian Roulette!\nUse /join to join the game.\nUse /start when all players have joined.", displayName(m.Sender)))
+	})
+
+	handle("/practice", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		if game := r.game; game != nil && game.IsActive {
+			sender.Send(m.Chat, "A game is already in progress!")
+			return
+		}
+		if refuseNewGame(sender, m.Chat) {
+			return
+		}
 
-		games[m.Chat.ID] = &Game{
-			Players:         []string{playerID},
-			Bullet:          rand.Intn(6),
-			CurrentPos:      0,
-			PullCount:       0,
-			IsActive:        true,
-			Skips:           map[string]int{playerID: 2},
-			HasPulledOnTurn: false,
+		cfg := chats.get(m.Chat.ID).Defaults
+		if err := parseCreateOptions(m.Payload, &cfg); err != nil {
+			sender.Send(m.Chat, errorMessage(err, nil))
+			return
 		}
+		cfg.Practice = true
 
-		bot.Send(m.Chat, fmt.Sprintf("🎮 @%s started a game of Russian Roulette!\nUse /join to join the game.\nUse /start when all players have joined.", m.Sender.Username))
+		game := createGame(m.Chat, getPlayerID(m.Sender), displayName(m.Sender), cfg)
+		scheduleJoinWindow(sender, m.Chat, game)
+		senderFor(sender, game).Send(m.Chat, fmt.Sprintf("🎮 %s opened a practice game! A death just reloads the cylinder and nothing counts towards the stats.\nUse /join to join the game.\nUse /start when all players have joined.", displayName(m.Sender)))
 	})
 
-	bot.Handle("/join", func(m *telebot.Message) {
-		mutex.Lock()
-		defer mutex.Unlock()
+	handle("/clone", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
 
-		game, exists := games[m.Chat.ID]
-		if !exists || !game.IsActive {
-			bot.Send(m.Chat, "No active game! Use /create to create a new game.")
+		if game := r.game; game != nil && game.IsActive {
+			sender.Send(m.Chat, "A game is already in progress!")
+			return
+		}
+		if refuseNewGame(sender, m.Chat) {
+			return
+		}
+		if r.lastConfig == nil {
+			sender.Send(m.Chat, "No game has been played here yet! Use /create to start one.")
+			return
+		}
+		cfg := *r.lastConfig
+
+		game := createGame(m.Chat, getPlayerID(m.Sender), displayName(m.Sender), cfg)
+		scheduleJoinWindow(sender, m.Chat, game)
+		sender.Send(m.Chat, fmt.Sprintf("🎮 %s opened a new game with the same settings as the last one: %s mode, %d bullet(s) in %d chambers, %d skip(s) each.\nUse /join to join the game.\nUse /start when all players have joined.",
+			displayName(m.Sender), cfg.Mode, cfg.Bullets, cfg.Chambers, cfg.SkipsPerPlayer))
+	})
+
+	handle("/join", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
 			return
 		}
 
 		playerID := getPlayerID(m.Sender)
 		log.Printf("Player trying to join: %s", playerID)
 
-		for _, player := range game.Players {
-			if player == playerID {
-				bot.Send(m.Chat, "You're already in the game!")
+		if err := game.Join(playerID, displayName(m.Sender)); err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		announceJoin(sender, m.Chat, game, playerID, "")
+	})
+
+	handle("/leave", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		if r.handled.seen(m.ID) {
+			return
+		}
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		playLeave(sender, m.Chat, game, getPlayerID(m.Sender))
+	})
+
+	handle("/start", func(m *telebot.Message) {
+		if m.Chat.Type == telebot.ChatPrivate && m.Payload != "" {
+			// Someone followed an /invite link
+			joinByInvite(sender, m)
+			return
+		}
+
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		startGame(sender, m.Chat, game)
+	})
+
+	handle("/sudden", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		playSudden(sender, m.Chat, game)
+	})
+
+	handle("/invite", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+		if game.Phase != PhaseLobby {
+			sender.Send(m.Chat, errorMessage(ErrGameAlreadyStarted, game))
+			return
+		}
+
+		sender.Send(m.Chat, "📨 Share this link to invite others to the game:\n"+inviteLink(bot.Me.Username, m.Chat.ID, game.Generation))
+	})
+
+	handle("/duel", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		names, err := parseMentions(m.Payload, 1)
+		if err != nil {
+			sender.Send(m.Chat, "Usage: /duel @player")
+			return
+		}
+
+		challengeDuel(sender, m.Chat, m.Sender, names[0])
+	})
+
+	handle("/accept", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		acceptDuel(sender, m.Chat, m.Sender)
+	})
+
+	handle("/mode", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		name := strings.ToLower(strings.TrimSpace(m.Payload))
+		if name == "" {
+			sender.Send(m.Chat, fmt.Sprintf("Current mode: %s\nUse /mode <name> to change it. Available modes:%s", game.Mode, modeList()))
+			return
+		}
+
+		if err := game.SetMode(Mode(name)); err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		sender.Send(m.Chat, fmt.Sprintf("Mode set to %s: %s", name, modes[game.Mode].Description))
+	})
+
+	handle("/addbot", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		if getPlayerID(m.Sender) != game.Creator {
+			sender.Send(m.Chat, "Only the game creator can add bot players!")
+			return
+		}
+
+		bp, err := parseBotPlayer(m.Payload)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		id, err := game.AddBot(bp)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		sender.Send(m.Chat, fmt.Sprintf("🤖 %s joined the game playing %s! Current players: %s", game.name(id), bp, game.nameList(game.Players)))
+	})
+
+	handle("/skip", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		if r.handled.seen(m.ID) {
+			return
+		}
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		game.peeker = m.Sender
+		playSkip(sender, m.Chat, game, getPlayerID(m.Sender))
+	})
+
+	handle("/pass", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		if r.handled.seen(m.ID) {
+			return
+		}
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		playPass(sender, m.Chat, game, getPlayerID(m.Sender))
+	})
+
+	handle("/pull", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		if r.handled.seen(m.ID) {
+			return
+		}
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		playerID := getPlayerID(m.Sender)
+		confirmed := strings.EqualFold(strings.TrimSpace(m.Payload), "confirm")
+		if err := game.ConfirmPull(playerID, confirmed); err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		game.trigger = m
+		playPull(sender, m.Chat, game, playerID)
+	})
+
+	handle("/nudge", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		playNudge(sender, m.Chat, game)
+	})
+
+	handle("/assistme", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		playerID := getPlayerID(m.Sender)
+		if err := game.AllowAssist(playerID); err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+		sender.Send(m.Chat, fmt.Sprintf("♿ %s allowed the game creator to pull for them with /pullfor.", game.name(playerID)))
+	})
+
+	handle("/pullfor", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		if r.handled.seen(m.ID) {
+			return
+		}
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+		if getPlayerID(m.Sender) != game.Creator {
+			sender.Send(m.Chat, "Only the game creator can pull for another player!")
+			return
+		}
+		names, err := parseMentions(m.Payload, 1)
+		if err != nil {
+			sender.Send(m.Chat, "Usage: /pullfor @player")
+			return
+		}
+
+		player := game.mentioned(names[0])
+		if err := game.CanAssist(player); err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+		senderFor(sender, game).Send(m.Chat, fmt.Sprintf("♿ The creator pulls for %s.", game.name(player)))
+		game.assisted = true
+		playPull(sender, m.Chat, game, player)
+	})
+
+	handle("/more", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+		playerID := getPlayerID(m.Sender)
+		if game.Phase == PhaseRunning && playerID != game.CurrentPlayer() && playerID != game.Creator {
+			sender.Send(m.Chat, "Only the current player or the game creator can ask for more time!")
+			return
+		}
+
+		if err := game.ExtendTurn(); err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+		scheduleTurnTimer(sender, m.Chat, game)
+		sender.Send(m.Chat, fmt.Sprintf("⏳ %s gets another %d seconds!", game.name(game.CurrentPlayer()), game.TurnTimeout))
+	})
+
+	handle("/voteharder", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		escalated, err := game.VoteHarder(getPlayerID(m.Sender))
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		if escalated && game.reloadsDuringPlay() {
+			sender.Send(m.Chat, "😈 The crowd wants blood! An extra bullet will be loaded when the cylinder is next reloaded.")
+			return
+		}
+		if escalated {
+			sender.Send(m.Chat, fmt.Sprintf("😈 The crowd wants blood! An extra bullet was loaded into the cylinder, the next pull is %.1f%% likely to be fatal.", game.NextOdds()))
+			return
+		}
+		sender.Send(m.Chat, fmt.Sprintf("🗳 Vote counted! %d/%d votes to add a bullet.", len(game.HarderVotes), voteHarderThreshold))
+	})
+
+	handle("/reload", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		if r.handled.seen(m.ID) {
+			return
+		}
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+		player := getPlayerID(m.Sender)
+		if strings.TrimSpace(m.Payload) == "" {
+			if err := game.Respin(player); err != nil {
+				sender.Send(m.Chat, errorMessage(err, game))
 				return
 			}
+			text := fmt.Sprintf("🔄 %s reloads the cylinder! %d bullet(s) in %d fresh chambers, the next pull is %.1f%% likely to be fatal.",
+				game.name(player), game.Bullets, game.Chambers, game.NextOdds())
+			if game.PendingReload != nil || game.PendingBullets > 0 {
+				text += "\nThe changes waiting for the next cylinder still apply when it runs out."
+			}
+			s := senderFor(sender, game)
+			s.Send(m.Chat, text, turnOptions(game)...)
+			showCylinder(s, m.Chat, game)
+			return
+		}
+		if player != game.Creator {
+			sender.Send(m.Chat, "Only the game creator can change the next cylinder!")
+			return
 		}
 
-		game.Players = append(game.Players, playerID)
-		game.Skips[playerID] = 2
-		bot.Send(m.Chat, fmt.Sprintf("@%s joined the game! Current players: %v", m.Sender.Username, game.Players))
+		next, err := game.SetReload(m.Payload)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+		senderFor(sender, game).Send(m.Chat, fmt.Sprintf("🔧 The next cylinder will have %d bullet(s) in %d chambers.", next.Bullets, next.Chambers))
 	})
 
-	bot.Handle("/start", func(m *telebot.Message) {
-		mutex.Lock()
-		game, exists := games[m.Chat.ID]
-		mutex.Unlock()
+	handle("/restart", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
 
-		if !exists || !game.IsActive {
-			bot.Send(m.Chat, "No active game! Use /create to create a new game.")
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
 			return
 		}
 
-		if len(game.Players) < 2 {
-			bot.Send(m.Chat, "Need at least 2 players to start!")
+		if getPlayerID(m.Sender) != game.Creator {
+			sender.Send(m.Chat, "Only the game creator can restart the game!")
 			return
 		}
 
-		bot.Send(m.Chat, "🎲 Game starting! Use /pull to take your turn (you can pull multiple times), /skip to skip your turn (max 2 skips per player), or /pass after pulling at least once.")
-		bot.Send(m.Chat, fmt.Sprintf("First up: @%s", game.Players[0]))
+		if err := game.Restart(); err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+
+		sender.Send(m.Chat, fmt.Sprintf("🔄 The game has been reset to the lobby with a fresh cylinder. Players: %s\nChange the /mode if needed and use /start to play again.", game.nameList(game.Players)))
 	})
 
-	bot.Handle("/skip", func(m *telebot.Message) {
-		mutex.Lock()
-		defer mutex.Unlock()
+	handle("/stop", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		game := r.game
+		active := game != nil && game.IsActive
+		creator, generation := "", uint64(0)
+		if active {
+			creator, generation = game.Creator, game.Generation
+		}
+		r.unlock()
 
-		game, exists := games[m.Chat.ID]
-		if !exists || !game.IsActive {
-			bot.Send(m.Chat, "No active game! Use /create to create a new game.")
+		if !active {
+			sender.Send(m.Chat, "No active game to stop!")
 			return
 		}
 
-		currentPlayer := game.Players[game.CurrentPos%len(game.Players)]
-		if getPlayerID(m.Sender) != currentPlayer {
-			bot.Send(m.Chat, fmt.Sprintf("It's not your turn! Waiting for @%s to play.", currentPlayer))
+		if !canManageGame(bot, m.Chat, m.Sender, creator) {
+			sender.Send(m.Chat, "Only the game creator or a chat admin can stop the game!")
 			return
 		}
 
-		if game.HasPulledOnTurn {
-			bot.Send(m.Chat, "You've already pulled the trigger this turn! Use /pass to end your turn.")
+		r = lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		game, err := liveGame(m.Chat.ID, generation)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
 			return
 		}
+		playStop(sender, m.Chat, game, strings.EqualFold(strings.TrimSpace(m.Payload), "yes"))
+	})
 
-		if game.Skips[currentPlayer] <= 0 {
-			bot.Send(m.Chat, "You have no skips remaining! You must /pull!")
+	handle("/queue", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		game := r.game
+		if game == nil || !game.IsActive {
+			sender.Send(m.Chat, "No game is running! Use /create to start one.")
+			return
+		}
+		if game.Phase == PhaseLobby {
+			sender.Send(m.Chat, "The game hasn't started yet, just /join it!")
 			return
 		}
 
-		game.Skips[currentPlayer]--
-		game.CurrentPos++
-		game.HasPulledOnTurn = false
-		nextPlayer := game.Players[game.CurrentPos%len(game.Players)]
+		if !enqueue(m.Chat.ID, queuedPlayer{ID: getPlayerID(m.Sender), Name: displayName(m.Sender)}) {
+			sender.Send(m.Chat, "You're already queued for the next game!")
+			return
+		}
+		sender.Send(m.Chat, fmt.Sprintf("📝 %s is queued for the next game (%d in line).", displayName(m.Sender), len(r.queue)))
+	})
 
-		skipsLeft := game.Skips[currentPlayer]
-		bot.Send(m.Chat, fmt.Sprintf("@%s skipped their turn! (%d skip(s) remaining)\nNext up: @%s",
-			currentPlayer, skipsLeft, nextPlayer))
+	handle("/taunt", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		taunt(sender, m.Chat, getPlayerID(m.Sender), m.Payload)
 	})
 
-	bot.Handle("/pass", func(m *telebot.Message) {
-		mutex.Lock()
-		defer mutex.Unlock()
+	handle("/stats", func(m *telebot.Message) {
+		player, name := getPlayerID(m.Sender), displayName(m.Sender)
+		if m.Payload != "" {
+			names, err := parseMentions(m.Payload, 1)
+			if err != nil {
+				sender.Send(m.Chat, "Usage: /stats or /stats @player")
+				return
+			}
+			player, name = stats.player(m.Chat.ID, names[0]), "@"+names[0]
+		}
+
+		sender.Send(m.Chat, formatPlayerStats(name, stats.get(m.Chat.ID, player)))
+	})
 
-		game, exists := games[m.Chat.ID]
-		if !exists || !game.IsActive {
-			bot.Send(m.Chat, "No active game! Use /create to create a new game.")
+	handle("/mystats", func(m *telebot.Message) {
+		if m.Chat.Type != telebot.ChatPrivate {
+			sender.Send(m.Chat, "Send me /mystats in a private chat to see your stats!")
 			return
 		}
 
-		currentPlayer := game.Players[game.CurrentPos%len(game.Players)]
-		if getPlayerID(m.Sender) != currentPlayer {
-			bot.Send(m.Chat, fmt.Sprintf("It's not your turn! Waiting for @%s to play.", currentPlayer))
+		sender.Send(m.Chat, formatOwnStats(stats.aggregate(getPlayerID(m.Sender))))
+	})
+
+	handle("/exportstats", func(m *telebot.Message) {
+		if !isChatAdmin(bot, m.Chat, m.Sender) {
+			sender.Send(m.Chat, "Only chat admins can export the stats!")
 			return
 		}
 
-		if !game.HasPulledOnTurn {
-			bot.Send(m.Chat, "You must pull the trigger at least once before passing!")
+		data, players := stats.leaderboardCSV(m.Chat.ID)
+
+		if players == 0 {
+			sender.Send(m.Chat, "No games have been played here yet!")
 			return
 		}
+		if err := sendCSV(sender, m.Chat, "leaderboard", data); err != nil {
+			log.Printf("Error exporting stats of chat %d: %v", m.Chat.ID, err)
+			sender.Send(m.Chat, "Sorry, the stats couldn't be exported.")
+		}
+	})
 
-		game.CurrentPos++
-		game.HasPulledOnTurn = false
-		nextPlayer := game.Players[game.CurrentPos%len(game.Players)]
-		bot.Send(m.Chat, fmt.Sprintf("@%s passed their turn.\nNext up: @%s", currentPlayer, nextPlayer))
+	handle("/replay", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		game := r.finished
+		if game == nil {
+			sender.Send(m.Chat, "No game has finished here recently!")
+			return
+		}
+		sendRecap(senderFor(sender, game), m.Chat, game)
 	})
 
-	bot.Handle("/pull", func(m *telebot.Message) {
-		mutex.Lock()
-		defer mutex.Unlock()
+	handle("/kick", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
 
-		game, exists := games[m.Chat.ID]
-		if !exists || !game.IsActive {
-			bot.Send(m.Chat, "No active game! Use /create to create a new game.")
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+		if getPlayerID(m.Sender) != game.Creator {
+			sender.Send(m.Chat, "Only the game creator can kick players!")
+			return
+		}
+		names, err := parseMentions(m.Payload, 1)
+		if err != nil {
+			sender.Send(m.Chat, "Usage: /kick @player")
+			return
+		}
+		player := game.mentioned(names[0])
+		if player == game.Creator {
+			sender.Send(m.Chat, "You can't kick yourself! Use /leave or /stop instead.")
 			return
 		}
 
-		currentPlayer := game.Players[game.CurrentPos%len(game.Players)]
-		if getPlayerID(m.Sender) != currentPlayer {
-			bot.Send(m.Chat, fmt.Sprintf("It's not your turn! Waiting for @%s to pull the trigger.", currentPlayer))
+		wasTurn := game.Phase == PhaseRunning && game.CurrentPlayer() == player
+		name := game.name(player)
+		ended, err := game.Kick(player)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
 			return
 		}
+		s := senderFor(sender, game)
+		if ended {
+			dead := slices.Clone(game.Eliminated)
+			announceEnd(s, m.Chat, fmt.Sprintf("👢 %s was kicked from the game. Game Over!", name)+winnerLine(game, dead...))
+			sendRecap(s, m.Chat, game)
+			endGame(s, m.Chat, game, dead...)
+			return
+		}
+		if !wasTurn {
+			s.Send(m.Chat, fmt.Sprintf("👢 %s was kicked from the game. Players: %s", name, game.nameList(game.Players)))
+			return
+		}
+		s.Send(m.Chat, fmt.Sprintf("👢 %s was kicked from the game.\n%s", name, nextUp(m.Chat, game)), turnOptions(game)...)
+		afterAction(s, m.Chat, game)
+	})
+
+	handle("/rematch", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
 
-		if game.PullCount == game.Bullet {
-			bot.Send(m.Chat, fmt.Sprintf("💥 BANG! @%s is dead! Game Over!", m.Sender.Username))
-			delete(games, m.Chat.ID)
+		if game := r.game; game != nil && game.IsActive {
+			sender.Send(m.Chat, "A game is already in progress!")
+			return
+		}
+		prev := r.finished
+		if prev == nil {
+			sender.Send(m.Chat, "No game has finished here recently! Use /create to start a new one.")
 			return
 		}
+		if !slices.Contains(prev.roster(), getPlayerID(m.Sender)) && getPlayerID(m.Sender) != prev.Creator {
+			sender.Send(m.Chat, "Only players of the last game can ask for a rematch!")
+			return
+		}
+		if refuseNewGame(sender, m.Chat) {
+			return
+		}
+		rematch(sender, m.Chat, prev)
+	})
 
-		remainingChambers := 6 - game.PullCount - 1
-		if remainingChambers <= 0 {
-			bot.Send(m.Chat, fmt.Sprintf("💥 BANG! @%s is dead! Game Over!", m.Sender.Username))
-			delete(games, m.Chat.ID)
+	handle("/compare", func(m *telebot.Message) {
+		names, err := parseMentions(m.Payload, 2)
+		if err != nil {
+			sender.Send(m.Chat, "Usage: /compare @player1 @player2")
 			return
 		}
 
-		oddsPercentage := (1.0 / float64(remainingChambers)) * 100
+		a, b := stats.player(m.Chat.ID, names[0]), stats.player(m.Chat.ID, names[1])
+		sender.Send(m.Chat, formatComparison("@"+names[0], "@"+names[1], stats.get(m.Chat.ID, a), stats.get(m.Chat.ID, b)))
+	})
 
-		game.HasPulledOnTurn = true
-		game.PullCount++
+	handle("/dashboard", func(m *telebot.Message) {
+		if !isOwner(m.Sender) || m.Chat.Type != telebot.ChatPrivate {
+			sender.Send(m.Chat, "Only the bot operator can see the dashboard, in a private chat with the bot!")
+			return
+		}
+		page := 1
+		if m.Payload != "" {
+			n, err := strconv.Atoi(strings.TrimSpace(m.Payload))
+			if err != nil || n < 1 {
+				sender.Send(m.Chat, "Usage: /dashboard [page]")
+				return
+			}
+			page = n
+		}
 
-		survivalMsg := fmt.Sprintf("*click* @%s survives!\nChambers left: %d\nChance of next shot being fatal: %.1f%%\nSkips remaining: %d\nUse /pull to try again or /pass to end your turn",
-			getPlayerID(m.Sender),
-			remainingChambers,
-			oddsPercentage,
-			game.Skips[currentPlayer])
-		bot.Send(m.Chat, survivalMsg)
+		sender.Send(m.Chat, renderDashboard(page, time.Now()))
 	})
 
-	bot.Handle("/stop", func(m *telebot.Message) {
-		mutex.Lock()
-		defer mutex.Unlock()
+	handle("/advantage", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
 
-		if game, exists := games[m.Chat.ID]; exists && game.IsActive {
-			delete(games, m.Chat.ID)
-			bot.Send(m.Chat, "Game stopped.")
-		} else {
-			bot.Send(m.Chat, "No active game to stop!")
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
 		}
+		if len(game.Players) < minPlayers {
+			sender.Send(m.Chat, errorMessage(ErrNotEnoughPlayers, game))
+			return
+		}
+		sender.Send(m.Chat, renderAdvantage(game))
 	})
 
-	bot.Handle("/help", func(m *telebot.Message) {
+	handle("/simulate", func(m *telebot.Message) {
+		if !isOwner(m.Sender) {
+			sender.Send(m.Chat, "Only the bot operator can run simulations!")
+			return
+		}
+		fields := strings.Fields(m.Payload)
+		n, players := 0, defaultSimPlayers
+		var err error
+		if len(fields) > 0 {
+			n, err = strconv.Atoi(fields[0])
+		}
+		if err == nil && len(fields) > 1 {
+			players, err = strconv.Atoi(fields[1])
+		}
+		if err != nil || len(fields) == 0 || len(fields) > 2 || n < 1 || n > maxSimulatedGames || players < minPlayers || players > maxSimulatedPlayers {
+			sender.Send(m.Chat, fmt.Sprintf("Usage: /simulate <games> [players], with up to %d games and %d to %d players", maxSimulatedGames, minPlayers, maxSimulatedPlayers))
+			return
+		}
+
+		cfg := chats.get(m.Chat.ID).Defaults
+
+		// Big simulations take a while, so they run without holding up other updates
+		go func() {
+			sender.Send(m.Chat, simulate(cfg, players, n, newRandomizer()).String())
+		}()
+	})
+
+	handle("/drain", func(m *telebot.Message) {
+		if !isOwner(m.Sender) {
+			sender.Send(m.Chat, "Only the bot operator can drain the bot!")
+			return
+		}
+		draining.Store(true)
+		sender.Send(m.Chat, fmt.Sprintf("🛠 Draining: no new games will start, %d game(s) still running.", activeGames()))
+	})
+
+	handle("/undrain", func(m *telebot.Message) {
+		if !isOwner(m.Sender) {
+			sender.Send(m.Chat, "Only the bot operator can undrain the bot!")
+			return
+		}
+		draining.Store(false)
+		sender.Send(m.Chat, "✅ New games can be started again.")
+	})
+
+	handle("/seed", func(m *telebot.Message) {
+		if !isChatAdmin(bot, m.Chat, m.Sender) {
+			sender.Send(m.Chat, "Only chat admins can see game seeds!")
+			return
+		}
+
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		sender.Send(m.Chat, seedMessage(m.Chat.ID))
+	})
+
+	handle("/config", func(m *telebot.Message) {
+		fields := strings.Fields(m.Payload)
+		if len(fields) != 2 {
+			sender.Send(m.Chat, "Usage: /config <setting> <value>, e.g. /config skips 3 or /config reveal on\nSettings:"+createOptionsHelp+"\ntz <zone> - timezone for timestamps, e.g. Europe/London\nreactions <on|off|only> - react to pulls with emoji, only replacing the survival message\nverbosity <minimal|normal|verbose> - how chatty I am during games\nsuffix <on|off> - only answer commands addressed to me, like /pull@"+bot.Me.Username)
+			return
+		}
+		fields[0] = strings.ToLower(fields[0])
+
+		if !isChatAdmin(bot, m.Chat, m.Sender) {
+			sender.Send(m.Chat, "Only chat admins can change the configuration!")
+			return
+		}
+
+		if err := chats.set(m.Chat.ID, fields[0], fields[1]); err != nil {
+			sender.Send(m.Chat, errorMessage(err, nil))
+			return
+		}
+
+		if fields[0] == "tz" {
+			sender.Send(m.Chat, fmt.Sprintf("⚙️ Timestamps are now shown in %s.", fields[1]))
+			return
+		}
+		if fields[0] == "reactions" {
+			sender.Send(m.Chat, fmt.Sprintf("⚙️ Reactions set to %s.", strings.ToLower(fields[1])))
+			return
+		}
+		if fields[0] == "verbosity" {
+			sender.Send(m.Chat, fmt.Sprintf("⚙️ Verbosity set to %s.", strings.ToLower(fields[1])))
+			return
+		}
+		if fields[0] == "suffix" {
+			if chats.get(m.Chat.ID).RequireSuffix {
+				sender.Send(m.Chat, fmt.Sprintf("⚙️ Commands in this chat must now be addressed to me, like /pull@%s.", bot.Me.Username))
+			} else {
+				sender.Send(m.Chat, fmt.Sprintf("⚙️ Commands in this chat no longer need the @%s suffix.", bot.Me.Username))
+			}
+			return
+		}
+		sender.Send(m.Chat, fmt.Sprintf("⚙️ %s set to %s for new games.", fields[0], strings.ToLower(fields[1])))
+	})
+
+	handle("/presets", func(m *telebot.Message) {
+		sender.Send(m.Chat, presetList())
+	})
+
+	handle("/rules", func(m *telebot.Message) {
+		sender.Send(m.Chat, renderRules(chats.get(m.Chat.ID).Defaults))
+	})
+
+	handle("/help", func(m *telebot.Message) {
 		helpText := `Game commands:
-/create - Start a new game
+/create [options] - Start a new game, e.g. /create brutal or /create 8 2
+/presets - List the named setups /create accepts
+/practice - Create a game for learning, where dying just reloads the cylinder
+/clone - Create a new game with the settings of the last one
 /join - Join the current game
+/leave - Leave the game before it starts
+/invite - Get a link that lets others join the game
+/duel @player - Challenge someone to a two-player game
+/accept - Accept a duel you were challenged to
+/mode - Choose the game variant before starting
+/addbot - Add a computer player, e.g. /addbot odds 40 (creator only)
 /start - Start the game after players have joined
-/stop - Stop the current game
+/sudden - Start the game and settle it at once, with everyone pulling together
+/voteharder - Spectators vote to load an extra bullet
+/reload chambers=<n> bullets=<n> - Change the cylinder from the next reload on (creator only)
+/restart - Reset a running game back to the lobby, keeping the players (creator only)
+/kick @player - Remove a player from the game for good (creator only). Kicking the last opponent ends the game
+/stop - Stop the current game, send it twice or as /stop yes to confirm (creator or admins only)
+/queue - Reserve a spot in the next game while one is running
+/taunt <message> - Taunt the survivors from the grave after you die
 /status - Show current game status
+/odds - Show how likely the next pull is to be fatal
+/replay - Show the recap of the game that just finished
+/rematch - Play the game that just finished again with the same players
+/cylinder - Show the live cylinder of the running game
+/advantage - Show how likely each seat of the game is to lose
+/stats [@player] - Show your or a player's record in this chat, with their recent trend
+/compare @a @b - Compare two players' records in this chat
+/mystats - See your own stats across every chat, in a private chat with me
+/exportstats - Get the leaderboard of this chat as a CSV file (admins only)
+/rules - Show the rules new games in this chat are played by
+/config - Change the default settings for this chat (admins only)
+/alias [add <alias> <command> | remove <alias>] - List or change this chat's command aliases (admins only)
+/seed - Show the random seed of the last finished game (admins only)
+/hardreset [confirm full] - Clear this chat's game, queue and pending duel, with full also the leaderboard (admins only)
+/dashboard - List the active games of every chat (bot operator only)
+/simulate <games> [players] - Simulate games to check their fairness (bot operator only)
+/drain, /undrain - Stop or resume starting new games before a deploy (bot operator only)
 
 Options during game:
 	/pull - Pull the trigger (can be used multiple times on your turn)
 	/pass - End your turn (only after pulling at least once)
 	/skip - Skip your turn (max 2 skips per player)
+	The Pull, Pass and Skip buttons under each turn announcement do the same
+	/reload - Load a fresh cylinder before you pull, once per game
+	/assistme - Allow the game creator to pull for you
+	/pullfor @player - Pull for a player who allowed it (creator only)
+	/more - Get more time for the current turn, once per turn
+	/nudge - Remind the current player that it's their turn
 
 /help - Show this help message`
-		bot.Send(m.Chat, helpText)
+		sender.Send(m.Chat, helpText)
 	})
 
-	bot.Handle("/status", func(m *telebot.Message) {
-		mutex.Lock()
-		defer mutex.Unlock()
+	handle("/cylinder", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
 
-		game, exists := games[m.Chat.ID]
-		if !exists || !game.IsActive {
-			bot.Send(m.Chat, "No active game!")
+		game := r.game
+		if game == nil || !game.IsActive {
+			sender.Send(m.Chat, "No active game!")
+			return
+		}
+		if game.Phase != PhaseRunning {
+			sender.Send(m.Chat, errorMessage(ErrGameNotStarted, game))
 			return
 		}
 
-		currentPlayer := game.Players[game.CurrentPos%len(game.Players)]
-		status := fmt.Sprintf("Current players: %v\nWaiting for: @%s\nSkips remaining: ", game.Players, currentPlayer)
+		// Post a fresh copy so the live cylinder is at the bottom of the chat again
+		postCylinder(sender, m.Chat, game, renderCylinder(game))
+	})
+
+	handle("/status", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		game := r.game
+		if game == nil || !game.IsActive {
+			sender.Send(m.Chat, "No active game!")
+			return
+		}
+
+		waiting := "/start"
+		if game.Phase == PhaseRunning {
+			waiting = game.name(game.CurrentPlayer())
+		}
+		status := fmt.Sprintf("Mode: %s\nCurrent players: %s\nWaiting for: %s\nSkips remaining: ", game.Mode, game.nameList(game.Players), waiting)
 
 		for _, player := range game.Players {
-			status += fmt.Sprintf("\n@%s: %d", player, game.Skips[player])
+			status += fmt.Sprintf("\n%s: %d", game.name(player), game.Skips[player])
+		}
+		if len(game.Eliminated) > 0 {
+			status += fmt.Sprintf("\nEliminated: %s", game.nameList(game.Eliminated))
 		}
 
-		bot.Send(m.Chat, status)
+		sender.Send(m.Chat, status)
+	})
+
+	handle("/odds", func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			sender.Send(m.Chat, errorMessage(err, game))
+			return
+		}
+		if game.Phase != PhaseRunning {
+			sender.Send(m.Chat, errorMessage(ErrGameNotStarted, game))
+			return
+		}
+		senderFor(sender, game).Send(m.Chat, fmt.Sprintf("🎯 %s is up. Chambers left: %d\nChance of the next pull being fatal: %.1f%%",
+			game.name(game.CurrentPlayer()), game.remainingChambers(), game.NextOdds()))
 	})
 
+	handle("/hardreset", func(m *telebot.Message) {
+		full := strings.EqualFold(strings.Join(strings.Fields(m.Payload), " "), "confirm full")
+		if m.Payload != "" && !full {
+			sender.Send(m.Chat, "Usage: /hardreset, or /hardreset confirm full to also wipe the leaderboard")
+			return
+		}
+		if !isOwner(m.Sender) && !isChatAdmin(bot, m.Chat, m.Sender) {
+			sender.Send(m.Chat, "Only chat admins can reset the bot!")
+			return
+		}
+
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+
+		if err := hardReset(m.Chat.ID, full); err != nil {
+			log.Printf("Error saving stats: %v", err)
+		}
+		if full {
+			sender.Send(m.Chat, "🧹 Everything was reset, the leaderboard and game history included.")
+			return
+		}
+		sender.Send(m.Chat, "🧹 The game, queue and any pending duel were cleared. The leaderboard was kept.")
+	})
+
+	handle("/alias", func(m *telebot.Message) {
+		fields := strings.Fields(m.Payload)
+		if len(fields) == 0 {
+			sender.Send(m.Chat, renderAliases(chats.get(m.Chat.ID).Aliases))
+			return
+		}
+
+		action := strings.ToLower(fields[0])
+		if !(action == "add" && len(fields) == 3) && !(action == "remove" && len(fields) == 2) {
+			sender.Send(m.Chat, "Usage: /alias add <alias> <command>, e.g. /alias add shoot pull, /alias remove <alias>, or /alias to list them")
+			return
+		}
+		if !isChatAdmin(bot, m.Chat, m.Sender) {
+			sender.Send(m.Chat, "Only chat admins can change the aliases!")
+			return
+		}
+
+		if action == "add" {
+			if err := chats.addAlias(m.Chat.ID, fields[1], fields[2]); err != nil {
+				sender.Send(m.Chat, errorMessage(err, nil))
+				return
+			}
+			sender.Send(m.Chat, fmt.Sprintf("🔀 /%s now runs /%s.", strings.ToLower(strings.TrimPrefix(fields[1], "/")), strings.ToLower(strings.TrimPrefix(fields[2], "/"))))
+			return
+		}
+		if err := chats.removeAlias(m.Chat.ID, fields[1]); err != nil {
+			sender.Send(m.Chat, errorMessage(err, nil))
+			return
+		}
+		sender.Send(m.Chat, fmt.Sprintf("🔀 Removed the /%s alias.", strings.ToLower(strings.TrimPrefix(fields[1], "/"))))
+	})
+
+	for _, button := range turnButtons {
+		button := button
+		bot.Handle(&button, wrapPress(wrap, pressTurnButton(bot, sender, button.Unique)))
+	}
+
+	// Commands without a handler of their own may be a chat's aliases
+	bot.Handle(telebot.OnText, runAlias)
+
+	// telebot hands edits to OnEdited only, never to the command handlers.
+	// Edited commands are deliberately not honoured.
+	bot.Handle(telebot.OnEdited, func(m *telebot.Message) {
+		if strings.HasPrefix(m.Text, "/") {
+			log.Printf("Ignoring edited command in chat %d: %q", m.Chat.ID, m.Text)
+		}
+	})
+
+	stopOnSignal(bot)
+	resumeGames(sender)
+	go gameState.saveEvery(gameSaveInterval)
 	log.Println("Bot started...")
 	bot.Start()
+
+	if err := gameState.save(); err != nil {
+		log.Printf("Error saving games: %v", err)
+	}
+	log.Println("Bot stopped")
 }
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestErrorMessages(t *testing.T) {
+	g := runningGame(t, defaultConfig(), lastInChamber())
+	seen := make(map[string]error)
+	for _, err := range []error{
+		ErrNotYourTurn, ErrAlreadyJoined, ErrNotInGame, ErrAlreadyPulled, ErrMustPullFirst, ErrNoSkips,
+		ErrNotEnoughPlayers, ErrGameNotStarted, ErrGameAlreadyStarted, ErrGameJustEnded, ErrUnknownOption,
+		ErrUnknownStrategy, ErrInvalidOption, ErrNotSpectator, ErrAlreadyVoted, ErrNoTurnTimer, ErrAlreadyExtended,
+		ErrNeedsConfirm, ErrKicked, ErrNoReloads, ErrGameFull, ErrEliminated, ErrNoConsent, ErrStaleGame, ErrUnknownMode,
+		ErrStaleTurn, ErrCylinderFull,
+	} {
+		msg := errorMessage(err, g)
+		if msg == "Something went wrong." {
+			t.Errorf("%v has no message of its own", err)
+		}
+		if other, ok := seen[msg]; ok {
+			t.Errorf("%v and %v share the message %q", err, other, msg)
+		}
+		seen[msg] = err
+	}
+
+	// Wrapped errors are recognised too
+	wrapped := fmt.Errorf("%w: chambers must be between 2 and 20", ErrInvalidOption)
+	if got := errorMessage(wrapped, nil); got != "Sorry, invalid game option: chambers must be between 2 and 20." {
+		t.Errorf("errorMessage(%v) = %q", wrapped, got)
+	}
+}
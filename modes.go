@@ -0,0 +1,56 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// Mode names a game variant selectable with /mode before the game starts
+type Mode string
+
+const (
+	ModeClassic  Mode = "classic"
+	ModeSpin     Mode = "spin"
+	ModeHardcore Mode = "hardcore"
+	ModeElim     Mode = "elim"
+)
+
+// modeSpec describes a variant and the settings it applies at /start
+type modeSpec struct {
+	Description string
+	apply       func(cfg *GameConfig)
+}
+
+var modes = map[Mode]modeSpec{
+	ModeClassic: {
+		Description: "one bullet, the cylinder keeps turning until someone dies",
+		apply:       func(cfg *GameConfig) {},
+	},
+	ModeSpin: {
+		Description: "the cylinder is spun before every pull, so the odds never change",
+		apply:       func(cfg *GameConfig) { cfg.SpinEach = true },
+	},
+	ModeHardcore: {
+		Description: "no skips, every turn means pulling the trigger",
+		apply:       func(cfg *GameConfig) { cfg.SkipsPerPlayer = 0 },
+	},
+	ModeElim: {
+		Description: "a death only eliminates the player, the last one standing wins",
+		apply:       func(cfg *GameConfig) { cfg.Elimination = true },
+	},
+}
+
+// modeList renders the available modes for help and error messages
+func modeList() string {
+	names := make([]string, 0, len(modes))
+	for name := range modes {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString("\n" + name + " - " + modes[Mode(name)].Description)
+	}
+	return b.String()
+}
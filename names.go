@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/tucnak/telebot"
+)
+
+// maxNameLength caps how many characters of a name are shown
+const maxNameLength = 32
+
+// displayName is how a user is shown in messages: their @username when they
+// have one, otherwise their name, so users without a username still render.
+// Only the shown name is sanitized, player IDs stay as they are.
+func displayName(u *telebot.User) string {
+	if u.Username != "" {
+		return "@" + u.Username
+	}
+	if name := sanitizeName(u.FirstName + " " + u.LastName); name != "" {
+		return name
+	}
+	return fmt.Sprintf("player%d", u.ID)
+}
+
+// sanitizeName makes a user chosen name safe to put inside a message. It drops
+// control and invisible formatting characters, such as direction overrides
+// that would reverse the rest of the line, collapses whitespace and caps the
+// length. Names with right-to-left letters are wrapped in a direction isolate
+// so they can't rearrange the text around them.
+func sanitizeName(name string) string {
+	var b strings.Builder
+	rtl := false
+	for _, r := range name {
+		// The zero width joiner holds emoji sequences like 👨‍👩‍👧 together
+		if unicode.IsControl(r) || (unicode.Is(unicode.Cf, r) && r != '\u200d') {
+			continue
+		}
+		rtl = rtl || unicode.In(r, unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana, unicode.Nko)
+		b.WriteRune(r)
+	}
+
+	clean := []rune(strings.Join(strings.Fields(b.String()), " "))
+	if len(clean) > maxNameLength {
+		clean = append(clean[:maxNameLength], '…')
+	}
+	if rtl {
+		// First strong isolate ... pop directional isolate
+		return "\u2068" + string(clean) + "\u2069"
+	}
+	return string(clean)
+}
+
+// name returns how a player of the game is shown in messages, with their
+// leaderboard medal if they have one
+func (g *Game) name(player string) string {
+	name, ok := g.Names[player]
+	if !ok {
+		name = "@" + player
+	}
+	if badge, ok := g.badges[player]; ok {
+		name += " " + badge
+	}
+	return name
+}
+
+// nameList joins the display names of players for a message
+func (g *Game) nameList(players []string) string {
+	names := make([]string, len(players))
+	for i, player := range players {
+		names[i] = g.name(player)
+	}
+	return strings.Join(names, ", ")
+}
+
+// mentioned returns the player of the game that "@mention" refers to: whoever
+// is shown by that @username or, lacking one, by that name. A mention that
+// matches nobody is returned as it is, so bots are still found by their ID.
+func (g *Game) mentioned(mention string) string {
+	for player, name := range g.Names {
+		if strings.EqualFold(name, "@"+mention) || strings.EqualFold(name, mention) {
+			return player
+		}
+	}
+	return mention
+}
+
+// answersTo reports whether "@mention" refers to u, by their username or, when
+// they have none, their first name
+func answersTo(u *telebot.User, mention string) bool {
+	if u.Username != "" {
+		return strings.EqualFold(u.Username, mention)
+	}
+	return strings.EqualFold(u.FirstName, mention)
+}
+
+// refreshName updates how the sender of m is shown in the chat's game, so a
+// player who changed their name between turns is announced by the new one
+func refreshName(m *telebot.Message) {
+	game := roomOf(m.Chat.ID).game
+	if game == nil {
+		return
+	}
+	player := getPlayerID(m.Sender)
+	if game.HasPlayer(player) {
+		game.Names[player] = displayName(m.Sender)
+	}
+}
+
+// withFreshName makes a handler refresh the sender's name before running
+func withFreshName(handler func(*telebot.Message)) func(*telebot.Message) {
+	return func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		refreshName(m)
+		r.unlock()
+		handler(m)
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tucnak/telebot"
+)
+
+func TestDisplayName(t *testing.T) {
+	tests := []struct {
+		name string
+		user *telebot.User
+		want string
+	}{
+		{"username", &telebot.User{ID: 1, Username: "alice", FirstName: "Alice"}, "@alice"},
+		{"markdown username", &telebot.User{ID: 2, Username: "bob_*the*_[builder]"}, "@bob_*the*_[builder]"},
+		{"first and last name", &telebot.User{ID: 3, FirstName: "Carol", LastName: "King"}, "Carol King"},
+		{"first name only", &telebot.User{ID: 4, FirstName: "Dave"}, "Dave"},
+		{"nothing at all", &telebot.User{ID: 5}, "player5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := displayName(tt.user); got != tt.want {
+				t.Errorf("displayName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeathMessageNames(t *testing.T) {
+	tests := []struct {
+		name string
+		user *telebot.User
+		want string
+	}{
+		{"no username", &telebot.User{ID: 7, FirstName: "Bob"}, "💥 BANG! Bob is dead! Game Over!"},
+		{"markdown username", &telebot.User{ID: 8, Username: "b_o*b"}, "💥 BANG! @b_o*b is dead! Game Over!"},
+		{"no name at all", &telebot.User{ID: 9}, "💥 BANG! player9 is dead! Game Over!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := testChat(t)
+			cfg := defaultConfig()
+			cfg.NoShuffle = true
+			player := getPlayerID(tt.user)
+			game := newGame(player, displayName(tt.user), cfg, &scriptedRandomizer{})
+			if err := game.Join("alice", "@alice"); err != nil {
+				t.Fatalf("Join() = %v", err)
+			}
+			if err := game.Start(); err != nil {
+				t.Fatalf("Start() = %v", err)
+			}
+			roomOf(chat.ID).game = game
+			s := &recordingSender{}
+
+			playPull(s, chat, game, player)
+
+			deaths := s.sentWith("BANG!")
+			if len(deaths) != 1 || !strings.HasPrefix(deaths[0], tt.want) {
+				t.Errorf("death messages = %q, want one starting %q", deaths, tt.want)
+			}
+			if strings.Contains(deaths[0], "@ is dead") {
+				t.Errorf("death message has an empty mention: %q", deaths[0])
+			}
+		})
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Alice", "Alice"},
+		{"right-to-left override", "Bob\u202egnp.exe", "Bobgnp.exe"},
+		{"zero width characters", "Ca\u200brol\ufeff", "Carol"},
+		{"control characters", "Da\nve\t!", "Dave!"},
+		{"whitespace collapsed", "  Erin   Smith ", "Erin Smith"},
+		{"emoji kept", "🔥🔥 Frank 👨\u200d👩\u200d👧", "🔥🔥 Frank 👨\u200d👩\u200d👧"},
+		{"right-to-left script isolated", "שלום", "\u2068שלום\u2069"},
+		{"arabic isolated", "Grace مرحبا", "\u2068Grace مرحبا\u2069"},
+		{"long name capped", strings.Repeat("x", maxNameLength+5), strings.Repeat("x", maxNameLength) + "…"},
+		{"only invisible", "\u200b\u202e", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeName(tt.in); got != tt.want {
+				t.Errorf("sanitizeName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplayNameSanitizesNames(t *testing.T) {
+	tests := []struct {
+		user *telebot.User
+		want string
+	}{
+		{&telebot.User{ID: 1, FirstName: "\u202eecilA"}, "ecilA"},
+		{&telebot.User{ID: 2, FirstName: "🎲🎲🎲", LastName: "🔫"}, "🎲🎲🎲 🔫"},
+		{&telebot.User{ID: 3, FirstName: "\u200b"}, "player3"},
+	}
+	for _, tt := range tests {
+		if got := displayName(tt.user); got != tt.want {
+			t.Errorf("displayName(%q) = %q, want %q", tt.user.FirstName, got, tt.want)
+		}
+	}
+}
+
+func TestNameChangeIsShownLive(t *testing.T) {
+	chat := testChat(t)
+	before := &telebot.User{ID: 4, FirstName: "Dave", LastName: "Smith"}
+	dave := getPlayerID(before)
+	game := lobbyGame(t, defaultConfig(), lastInChamber())
+	game.Join(dave, displayName(before))
+	game.Start()
+	game.Generation = nextGeneration()
+	roomOf(chat.ID).game = game
+	s := &recordingSender{}
+
+	// dave renames himself between turns, which keeps his player ID
+	after := &telebot.User{ID: 4, FirstName: "Dave", LastName: "Jones"}
+	var played string
+	handler := withFreshName(func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+		played = getPlayerID(m.Sender)
+		playSkip(s, m.Chat, game, played)
+	})
+
+	playSkip(s, chat, game, "alice")
+	playSkip(s, chat, game, "bob")
+	playSkip(s, chat, game, "carol")
+	handler(&telebot.Message{ID: 1, Chat: chat, Sender: after, Text: "/skip"})
+
+	if played != dave || game.Names[dave] != "Dave Jones" {
+		t.Fatalf("dave played as %q named %q, want %q named Dave Jones", played, game.Names[dave], dave)
+	}
+	if got := s.sentWith("Dave Jones skipped their turn!"); len(got) != 1 {
+		t.Errorf("the skip wasn't announced by the new name: %q", s.sent)
+	}
+	if got := s.sentWith("Dave Smith"); len(got) != 1 {
+		t.Errorf("the old name was used %d time(s) after the change, want only before it: %q", len(got), got)
+	}
+
+	// The new name doesn't let dave play out of turn
+	handler(&telebot.Message{ID: 2, Chat: chat, Sender: after, Text: "/skip"})
+	if game.CurrentPlayer() != "alice" || game.Skips[dave] != defaultSkips-1 {
+		t.Errorf("dave skipped out of turn: %s is up, dave has %d skip(s)", game.CurrentPlayer(), game.Skips[dave])
+	}
+	if got := s.sentWith("It's not your turn!"); len(got) != 1 {
+		t.Errorf("the skip out of turn wasn't refused: %q", s.sent)
+	}
+}
+
+func TestUsernameChangeKeepsTheSeat(t *testing.T) {
+	chat := testChat(t)
+	before := &telebot.User{ID: 4, Username: "dave"}
+	dave := getPlayerID(before)
+	game := lobbyGame(t, defaultConfig(), lastInChamber())
+	game.Join(dave, displayName(before))
+	game.Start()
+	game.Generation = nextGeneration()
+	roomOf(chat.ID).game = game
+	s := &recordingSender{}
+
+	pull := withFreshName(func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+		playPull(s, m.Chat, game, getPlayerID(m.Sender))
+	})
+
+	playSkip(s, chat, game, "alice")
+	playSkip(s, chat, game, "bob")
+	playSkip(s, chat, game, "carol")
+	after := &telebot.User{ID: 4, Username: "dave_2"}
+	pull(&telebot.Message{ID: 1, Chat: chat, Sender: after, Text: "/pull"})
+
+	if game.PullCount != 1 || game.CurrentPlayer() != dave {
+		t.Fatalf("after the renamed /pull: %d pull(s), %s is up, want dave's pull", game.PullCount, game.CurrentPlayer())
+	}
+	if game.Names[dave] != "@dave_2" || game.Skips[dave] != defaultSkips {
+		t.Errorf("dave is %q with %d skip(s), want @dave_2 with %d", game.Names[dave], game.Skips[dave], defaultSkips)
+	}
+	if got := s.sentWith("@dave_2"); len(got) == 0 {
+		t.Errorf("the pull wasn't announced by the new username: %q", s.sent)
+	}
+	if got := game.mentioned("Dave_2"); got != dave {
+		t.Errorf("mentioned(Dave_2) = %q, want %q", got, dave)
+	}
+}
+
+func TestMentioned(t *testing.T) {
+	game := lobbyGame(t, defaultConfig(), lastInChamber())
+	game.Join("7", "@erin")
+	game.Join("8", "Frank")
+
+	tests := []struct {
+		mention string
+		want    string
+	}{
+		{"erin", "7"},
+		{"ERIN", "7"},
+		{"frank", "8"},
+		{"Alice", "alice"},
+		{"bot1", "bot1"},
+		{"nobody", "nobody"},
+	}
+	for _, tt := range tests {
+		if got := game.mentioned(tt.mention); got != tt.want {
+			t.Errorf("mentioned(%q) = %q, want %q", tt.mention, got, tt.want)
+		}
+	}
+}
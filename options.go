@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// createOptionsHelp lists the options /create understands
+const createOptionsHelp = `
+<preset> - start from a named setup, see /presets
+<chambers> [bullets] [skips] - numbers set the cylinder and skips directly
+mode=<name> - the variant to play, see /mode
+chambers=<n> - size of the cylinder
+bullets=<n> - bullets loaded into the cylinder
+skips=<n> - skips each player gets
+maxplayers=<n> - most players that can join, 0 for no limit
+fairstart - give players with worse luck a better chance of going first
+jam=<percent> - chance that a /skip jams and forces a pull
+jamrefund - a jammed skip isn't used up
+safepulls=<n> - the first n pulls of each cylinder are always safe
+reveal - say which chamber each survived pull cleared
+host - run the game without playing in it yourself
+house=<percent> - chance that the house secretly moves the bullets before a pull
+timer=<seconds> - time limit for each turn, 0 for none
+practice - deaths just reload the cylinder and no stats are kept
+latejoin - players may still /join after the game started, taking the last seat
+peekskip - a /skip privately tells you whether the next chamber is loaded
+noshuffle - play in join order instead of a random turn order
+elim - a death only eliminates the player and the game goes on until one survivor is left
+clutch=<percent> - surviving a pull at least this likely to be fatal earns clutch points, 0 for none
+joinwindow=<seconds> - start the game by itself this long after it was created, 0 to wait for /start
+joinreminder=<seconds> - remind the chat this long before joining closes
+confirm=<percent> - pulls at least this likely to be fatal must be confirmed with /pull confirm`
+
+// defaultClutchOdds is the fatal odds from which surviving a pull earns clutch points
+const defaultClutchOdds = 50
+
+// defaultMaxPlayers keeps turns coming round in big groups
+const defaultMaxPlayers = 10
+
+// maxSkips caps skips per player so games can't be stalled forever
+const maxSkips = 10
+
+// defaultConfig is the configuration a game gets when /create has no options
+func defaultConfig() GameConfig {
+	return GameConfig{
+		Mode:           ModeClassic,
+		SkipsPerPlayer: defaultSkips,
+		Chambers:       defaultChambers,
+		Bullets:        1,
+		ClutchOdds:     defaultClutchOdds,
+		JoinReminder:   defaultJoinReminder,
+		MaxPlayers:     defaultMaxPlayers,
+		TurnTimeout:    defaultTurnTimeout,
+	}
+}
+
+// flagOptions are the options that switch a setting on just by being named
+var flagOptions = map[string]func(cfg *GameConfig) *bool{
+	"fairstart": func(cfg *GameConfig) *bool { return &cfg.FairStart },
+	"jamrefund": func(cfg *GameConfig) *bool { return &cfg.JamRefund },
+	"reveal":    func(cfg *GameConfig) *bool { return &cfg.Reveal },
+	"host":      func(cfg *GameConfig) *bool { return &cfg.Host },
+	"practice":  func(cfg *GameConfig) *bool { return &cfg.Practice },
+	"latejoin":  func(cfg *GameConfig) *bool { return &cfg.LateJoin },
+	"peekskip":  func(cfg *GameConfig) *bool { return &cfg.PeekSkip },
+	"elim":      func(cfg *GameConfig) *bool { return &cfg.Elimination },
+	"noshuffle": func(cfg *GameConfig) *bool { return &cfg.NoShuffle },
+}
+
+// positionalOptions are the settings bare numbers given to /create fill, in order
+var positionalOptions = []string{"chambers", "bullets", "skips"}
+
+// parseCreateOptions applies the space separated options given to /create
+func parseCreateOptions(payload string, cfg *GameConfig) error {
+	positional := 0
+	for _, opt := range strings.Fields(strings.ToLower(payload)) {
+		key, value, hasValue := strings.Cut(opt, "=")
+		if hasValue {
+			if err := applyValueOption(key, value, cfg); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := strconv.Atoi(opt); err == nil {
+			if positional == len(positionalOptions) {
+				return fmt.Errorf("%w: too many numbers, expected chambers, bullets and skips", ErrInvalidOption)
+			}
+			if err := applyValueOption(positionalOptions[positional], opt, cfg); err != nil {
+				return err
+			}
+			positional++
+			continue
+		}
+
+		if p, ok := presets[opt]; ok {
+			p.apply(cfg)
+			continue
+		}
+
+		flag, ok := flagOptions[opt]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownOption, opt)
+		}
+		*flag(cfg) = true
+	}
+	return cfg.Validate()
+}
+
+// setOption sets a single option to value, with flags taking on or off
+func setOption(key, value string, cfg *GameConfig) error {
+	flag, ok := flagOptions[key]
+	if !ok {
+		if err := applyValueOption(key, value, cfg); err != nil {
+			return err
+		}
+		return cfg.Validate()
+	}
+
+	switch value {
+	case "on", "yes", "true":
+		*flag(cfg) = true
+	case "off", "no", "false":
+		*flag(cfg) = false
+	default:
+		return fmt.Errorf("%w: %s must be on or off", ErrInvalidOption, key)
+	}
+	return cfg.Validate()
+}
+
+// applyValueOption handles options given as key=value. Combinations are
+// checked separately by GameConfig.Validate.
+func applyValueOption(key, value string, cfg *GameConfig) error {
+	switch key {
+	case "mode":
+		if _, ok := modes[Mode(value)]; !ok {
+			return ErrUnknownMode
+		}
+		cfg.Mode = Mode(value)
+	case "skips":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n > maxSkips {
+			return fmt.Errorf("%w: skips must be between 0 and %d", ErrInvalidOption, maxSkips)
+		}
+		cfg.SkipsPerPlayer = n
+	case "jam", "house", "confirm", "clutch":
+		percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+		if err != nil || percent < 0 || percent > 100 {
+			return fmt.Errorf("%w: %s must be a percentage between 0 and 100", ErrInvalidOption, key)
+		}
+		switch key {
+		case "jam":
+			cfg.JamChance = percent
+		case "house":
+			cfg.HouseEdge = percent
+		case "clutch":
+			cfg.ClutchOdds = percent
+		default:
+			cfg.ConfirmOdds = percent
+		}
+	case "timer":
+		n, err := strconv.Atoi(strings.TrimSuffix(value, "s"))
+		if err != nil || (n != 0 && (n < minTurnTimeout || n > maxTurnTimeout)) {
+			return fmt.Errorf("%w: timer must be 0 or between %d and %d seconds", ErrInvalidOption, minTurnTimeout, maxTurnTimeout)
+		}
+		cfg.TurnTimeout = n
+	case "joinwindow", "joinreminder":
+		n, err := strconv.Atoi(strings.TrimSuffix(value, "s"))
+		if err != nil || n < 0 || n > maxJoinWindow {
+			return fmt.Errorf("%w: %s must be between 0 and %d seconds", ErrInvalidOption, key, maxJoinWindow)
+		}
+		if key == "joinwindow" {
+			cfg.JoinWindow = n
+		} else {
+			cfg.JoinReminder = n
+		}
+	case "maxplayers":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%w: maxplayers must be a number", ErrInvalidOption)
+		}
+		cfg.MaxPlayers = n
+	case "chambers", "bullets", "safepulls":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%w: %s must be a number", ErrInvalidOption, key)
+		}
+		switch key {
+		case "chambers":
+			cfg.Chambers = n
+		case "bullets":
+			cfg.Bullets = n
+		default:
+			cfg.SafePulls = n
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownOption, key)
+	}
+	return nil
+}
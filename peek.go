@@ -0,0 +1,38 @@
+package main
+
+import (
+	"log"
+
+	"github.com/tucnak/telebot"
+)
+
+// Peek tells whether the next pull would fire with the cylinder as it is loaded
+// now. A cylinder that is spun before every pull can't be peeked at, which ok
+// reports as false.
+func (g *Game) Peek() (loaded, ok bool) {
+	if g.SpinEach {
+		return false, false
+	}
+	return g.PullCount >= len(g.Cylinder) || g.Cylinder[g.PullCount], true
+}
+
+// sendPeek privately tells user, who just skipped, whether the next chamber is
+// loaded. If they never opened a private chat with the bot it can't reach them,
+// so the group is told instead, without the secret.
+func sendPeek(s Sender, chat *telebot.Chat, game *Game, user *telebot.User) {
+	loaded, ok := game.Peek()
+	msg := "👀 The cylinder is spun before every pull, so there is nothing to peek at."
+	if ok && loaded {
+		msg = "👀 You peek at the cylinder: the next chamber holds a bullet!"
+	} else if ok {
+		msg = "👀 You peek at the cylinder: the next chamber is empty."
+	}
+	if game.HouseEdge > 0 && ok {
+		msg += "\nBut the house cheats, so it may have changed by the next pull."
+	}
+
+	if _, err := s.Send(user, msg); err != nil {
+		log.Printf("Error sending peek to %d: %v", user.ID, err)
+		s.Send(chat, "I couldn't send you the peek privately! Open a private chat with me first to get it next time.")
+	}
+}
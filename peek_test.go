@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tucnak/telebot"
+)
+
+func TestPeek(t *testing.T) {
+	tests := []struct {
+		name       string
+		bullet     int // Chamber the bullet is loaded in
+		pulls      int // Pulls survived before the peek
+		spinEach   bool
+		wantLoaded bool
+		wantOK     bool
+	}{
+		{"bullet next", 0, 0, false, true, true},
+		{"bullet later", 3, 0, false, false, true},
+		{"bullet after two pulls", 2, 2, false, true, true},
+		{"bullet passed by", 4, 2, false, false, true},
+		{"spun every pull", 0, 0, true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.SpinEach = tt.spinEach
+			g := runningGame(t, cfg, &scriptedRandomizer{values: []int{tt.bullet}})
+			g.PullCount = tt.pulls
+			if loaded, ok := g.Peek(); loaded != tt.wantLoaded || ok != tt.wantOK {
+				t.Errorf("Peek() = %t, %t, want %t, %t", loaded, ok, tt.wantLoaded, tt.wantOK)
+			}
+		})
+	}
+}
+
+// noPrivateSender is a recordingSender that can't reach users privately
+type noPrivateSender struct {
+	recordingSender
+}
+
+func (s *noPrivateSender) Send(to telebot.Recipient, what interface{}, options ...interface{}) (*telebot.Message, error) {
+	if _, private := to.(*telebot.User); private {
+		return nil, errors.New("bot can't initiate conversation with a user")
+	}
+	return s.recordingSender.Send(to, what, options...)
+}
+
+func TestSkipPeeks(t *testing.T) {
+	alice := &telebot.User{ID: 1, Username: "alice"}
+	tests := []struct {
+		name     string
+		bullet   int
+		peekSkip bool
+		sender   interface {
+			Sender
+			sentWith(string) []string
+		}
+		want    string // Sent once, "" for no peek at all
+		notWant string
+	}{
+		{"empty chamber", 3, true, &recordingSender{}, "the next chamber is empty.", ""},
+		// alice skipped, so the bullet in the first chamber is bob's to face
+		{"loaded chamber", 0, true, &recordingSender{}, "the next chamber holds a bullet!", ""},
+		{"no private chat", 0, true, &noPrivateSender{}, "I couldn't send you the peek privately!", "holds a bullet"},
+		{"peeking off", 0, false, &recordingSender{}, "", "peek"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := testChat(t)
+			cfg := defaultConfig()
+			cfg.PeekSkip = tt.peekSkip
+			game := playChat(t, chat, cfg, &scriptedRandomizer{values: []int{tt.bullet}})
+
+			// What /skip does before playing the skip
+			game.peeker = alice
+			playSkip(tt.sender, chat, game, "alice")
+
+			if tt.want != "" && len(tt.sender.sentWith(tt.want)) != 1 {
+				t.Errorf("nothing sent with %q", tt.want)
+			}
+			if tt.notWant != "" && len(tt.sender.sentWith(tt.notWant)) != 0 {
+				t.Errorf("sent %q", tt.sender.sentWith(tt.notWant))
+			}
+			if game.peeker != nil {
+				t.Error("the skip left the peeker set")
+			}
+		})
+	}
+}
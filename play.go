@@ -0,0 +1,301 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+// createGame sets up a new game in the chat's lobby
+func createGame(chat *telebot.Chat, creator, creatorName string, cfg GameConfig) *Game {
+	log.Printf("New game started by player: %s", creator)
+	game := newGame(creator, creatorName, cfg, newRandomizer())
+	game.Generation = nextGeneration()
+	roomOf(chat.ID).game = game
+	return game
+}
+
+// activeGame returns the chat's game, or ErrNoActiveGame if it has none in
+// play and ErrGameJustEnded if its last game finished moments ago. The game is
+// checked for inconsistencies first and repaired or ended if it has any.
+func activeGame(chatID int64) (*Game, error) {
+	r := roomOf(chatID)
+	game := r.game
+	if game == nil || !game.IsActive {
+		if r.finished != nil {
+			return nil, ErrGameJustEnded
+		}
+		return nil, ErrNoActiveGame
+	}
+	if !repair(chatID, game) {
+		return nil, ErrNoActiveGame
+	}
+	return game, nil
+}
+
+// liveGame returns the chat's game if it is still active and of the generation
+// the caller last saw, and ErrStaleGame if it has ended or been replaced since
+func liveGame(chatID int64, generation uint64) (*Game, error) {
+	game := roomOf(chatID).game
+	if game == nil || !game.IsActive || game.Generation != generation {
+		return nil, ErrStaleGame
+	}
+	return game, nil
+}
+
+// startGame moves a game from the lobby into play and announces the first turn
+func startGame(s Sender, chat *telebot.Chat, game *Game) {
+	s = senderFor(s, game)
+	if err := game.Start(); err != nil {
+		s.Send(chat, errorMessage(err, game))
+		return
+	}
+	stopJoinWindow(game)
+	rememberSettings(chat.ID, game)
+	game.badges = stats.badges(chat.ID)
+	if game.FairStart {
+		game.setFirstPlayer(pickFairFirst(chat.ID, game))
+	}
+
+	s.Send(chat, fmt.Sprintf("🎲 Game starting in %s mode with %d bullet(s) in %d chambers! Use /pull to take your turn (you can pull multiple times), /skip to skip your turn (max %d skips per player), or /pass after pulling at least once.",
+		game.Mode, game.Bullets, game.Chambers, game.SkipsPerPlayer))
+	s.Send(chat, fmt.Sprintf("Turn order: %s\nFirst up: %s", game.nameList(game.Players), game.name(game.CurrentPlayer())), turnOptions(game)...)
+	showCylinder(s, chat, game)
+	afterAction(s, chat, game)
+}
+
+// rememberSettings keeps the settings a game started with, its mode and any
+// /mode change in the lobby included, for /clone to open the next game with.
+// The caller has the chat locked.
+func rememberSettings(chatID int64, game *Game) {
+	cfg := game.StartConfig
+	roomOf(chatID).lastConfig = &cfg
+}
+
+// The play functions perform a turn action for player and announce the outcome.
+// They are shared by the command handlers and the bot players, and the caller
+// must have the chat locked.
+
+func playSkip(s Sender, chat *telebot.Chat, game *Game, player string) {
+	s = senderFor(s, game)
+	peeker := game.peeker
+	game.peeker = nil
+	jammed, err := game.Skip(player)
+	if err != nil {
+		s.Send(chat, errorMessage(err, game))
+		return
+	}
+
+	if jammed {
+		s.Send(chat, fmt.Sprintf("🔧 The skip jammed! %s has to pull the trigger! (%d skip(s) remaining)",
+			game.name(player), game.Skips[player]))
+		playPull(s, chat, game, player)
+		return
+	}
+
+	if chatty(chat.ID, verbosityNormal) {
+		s.Send(chat, fmt.Sprintf("%s skipped their turn! (%d skip(s) remaining)\n%s",
+			game.name(player), game.Skips[player], nextUp(chat, game)), turnOptions(game)...)
+	} else {
+		s.Send(chat, nextUp(chat, game), turnOptions(game)...)
+	}
+	if game.PeekSkip && peeker != nil {
+		sendPeek(s, chat, game, peeker)
+	}
+	afterAction(s, chat, game)
+}
+
+func playPass(s Sender, chat *telebot.Chat, game *Game, player string) {
+	s = senderFor(s, game)
+	if err := game.Pass(player); err != nil {
+		s.Send(chat, errorMessage(err, game))
+		return
+	}
+
+	s.Send(chat, fmt.Sprintf("%s passed their turn.\n%s", game.name(player), nextUp(chat, game)), turnOptions(game)...)
+	afterAction(s, chat, game)
+}
+
+func playPull(s Sender, chat *telebot.Chat, game *Game, player string) {
+	s = senderFor(s, game)
+	trigger := game.trigger
+	game.trigger = nil
+	result, err := game.Pull(player)
+	if err != nil {
+		s.Send(chat, errorMessage(err, game))
+		return
+	}
+
+	if result.Dead && game.Practice {
+		s.Send(chat, fmt.Sprintf("💥 BANG! %s would be dead! The cylinder has been reloaded, keep practicing.\nNext up: %s",
+			game.name(player), game.name(game.CurrentPlayer())), turnOptions(game)...)
+		showCylinder(s, chat, game)
+		afterAction(s, chat, game)
+		return
+	}
+	if result.Eliminated {
+		bury(chat.ID, game, player)
+		reactToPull(s, chat, trigger, reactionDeath)
+		s.Send(chat, fmt.Sprintf("💥 BANG! %s is eliminated! %d players left, the cylinder has been reloaded.\n%s",
+			game.name(player), len(game.Players), nextUp(chat, game)), turnOptions(game)...)
+		showCylinder(s, chat, game)
+		afterAction(s, chat, game)
+		return
+	}
+	if result.Dead {
+		dead := []string{player}
+		if game.Elimination {
+			dead = game.Eliminated
+		}
+		reactToPull(s, chat, trigger, reactionDeath)
+		announceEnd(s, chat, fmt.Sprintf("💥 BANG! %s is dead! Game Over!", game.name(player))+winnerLine(game, dead...))
+		sendRecap(s, chat, game)
+		endGame(s, chat, game, dead...)
+		return
+	}
+
+	if result.AddedBullets > 0 {
+		s.Send(chat, fmt.Sprintf("😈 By popular demand the cylinder now holds %d bullet(s)!", game.Bullets))
+	}
+
+	survivalMsg := fmt.Sprintf("*click* %s survives!", game.name(player))
+	if chatty(chat.ID, verbosityNormal) {
+		survivalMsg += fmt.Sprintf("\nChambers left: %d\nChance of next shot being fatal: %.1f%%\nSkips remaining: %d\nUse /pull to try again or /pass to end your turn",
+			result.RemainingChambers,
+			result.Odds,
+			game.Skips[player])
+	}
+	if game.Reveal {
+		survivalMsg += revealHint(game, result)
+	}
+	if bonus := stats.recordClutch(chat.ID, game, player, result.FacedOdds); bonus > 0 {
+		survivalMsg += fmt.Sprintf("\n🔥 Clutch survival at %.1f%% odds, +%d point(s)!", result.FacedOdds, bonus)
+	}
+	reacted := reactToPull(s, chat, trigger, reactionSurvive)
+	if !reacted || chats.get(chat.ID).Reactions != reactionsOnly {
+		s.Send(chat, survivalMsg, turnOptions(game)...)
+	}
+	showCylinder(s, chat, game)
+	afterAction(s, chat, game)
+}
+
+func playLeave(s Sender, chat *telebot.Chat, game *Game, player string) {
+	s = senderFor(s, game)
+	if err := game.Leave(player); err != nil {
+		if errors.Is(err, ErrGameAlreadyStarted) {
+			s.Send(chat, "The game has already started, so you can't leave it now!")
+			return
+		}
+		s.Send(chat, errorMessage(err, game))
+		return
+	}
+
+	chatter(s, chat, verbosityNormal, fmt.Sprintf("%s left the game. Current players: %s", game.name(player), game.nameList(game.Players)))
+	if len(game.Players) == 0 {
+		announceEnd(s, chat, "Everyone left, so the game is closed.")
+		roomOf(chat.ID).game = nil
+		startQueuedGame(s, chat)
+	}
+}
+
+// playNudge reminds the current player that it's their turn, at most once
+// every nudgeCooldown
+func playNudge(s Sender, chat *telebot.Chat, game *Game) {
+	if game.Phase != PhaseRunning {
+		s.Send(chat, errorMessage(ErrGameNotStarted, game))
+		return
+	}
+	if wait := nudgeCooldown - time.Since(game.lastNudge); wait > 0 {
+		s.Send(chat, fmt.Sprintf("They were just nudged! Try again in %d second(s).", int(wait.Seconds())+1))
+		return
+	}
+
+	game.lastNudge = time.Now()
+	senderFor(s, game).Send(chat, fmt.Sprintf("👉 %s, it's your turn!", game.name(game.CurrentPlayer())))
+}
+
+// stopConfirmWindow is how long a /stop waits for the second /stop that confirms it
+const stopConfirmWindow = 10 * time.Second
+
+// playStop ends the game once the stop is confirmed, either by /stop yes or by
+// a second /stop within stopConfirmWindow. An unconfirmed stop only asks for
+// the confirmation.
+func playStop(s Sender, chat *telebot.Chat, game *Game, confirmed bool) {
+	if !confirmed && time.Since(game.stopArmed) > stopConfirmWindow {
+		game.stopArmed = time.Now()
+		s.Send(chat, fmt.Sprintf("⚠️ Really stop the game? Send /stop again within %d seconds, or /stop yes, to confirm.", int(stopConfirmWindow/time.Second)))
+		return
+	}
+	roomOf(chat.ID).game = nil
+	s.Send(chat, "Game stopped.")
+	startQueuedGame(s, chat)
+}
+
+// announceJoin tells the chat that player joined. Joining a running game is
+// always announced with the odds recomputed for everyone, since nobody else
+// would know the turn order changed.
+func announceJoin(s Sender, chat *telebot.Chat, game *Game, player, how string) {
+	if game.Phase != PhaseRunning {
+		chatter(s, chat, verbosityNormal, fmt.Sprintf("%s joined the game%s! Current players: %s", game.name(player), how, game.nameList(game.Players)))
+		return
+	}
+	s.Send(chat, fmt.Sprintf("%s joined the running game%s and takes the last seat of the round. The cylinder is unchanged: %d chamber(s) left with %d bullet(s), so the next pull is %.1f%% likely to be fatal.\nTurn order: %s",
+		game.name(player), how, game.remainingChambers(), game.remainingBullets(), game.NextOdds(), game.nameList(game.Players)))
+}
+
+// winnerLine names the winner of a game that ended with the death of dead, or
+// lists the survivors if several are left
+func winnerLine(game *Game, dead ...string) string {
+	survivors := game.survivors(dead...)
+	switch len(survivors) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("\n🏆 %s wins!", game.name(survivors[0]))
+	}
+	return fmt.Sprintf("\n🎉 Survivors: %s", game.nameList(survivors))
+}
+
+// sendRecap posts the summary and awards of a finished game
+func sendRecap(s Sender, chat *telebot.Chat, game *Game) {
+	recap := gameSummary(game, chats.get(chat.ID).location())
+	if awards := gameAwards(game); awards != "" {
+		recap += "\n\n" + awards
+	}
+	s.Send(chat, recap)
+}
+
+// endGame records the finished game in the stats and removes it from the
+// chat, opening the next game if players are queued for it
+func endGame(s Sender, chat *telebot.Chat, game *Game, dead ...string) {
+	log.Printf("Game in chat %d ended with seed %d", chat.ID, game.Seed)
+	r := roomOf(chat.ID)
+	r.lastSeed, r.seeded = game.Seed, true
+
+	stats.recordGame(chat.ID, game, dead...)
+	if err := stats.save(); err != nil {
+		log.Printf("Error saving stats: %v", err)
+	}
+	bury(chat.ID, game, dead...)
+	retainFinished(chat.ID, game)
+	r.game = nil
+	startQueuedGame(s, chat)
+}
+
+// afterAction runs once the game state has changed, letting a bot player take
+// its move and restarting the turn timer
+func afterAction(s Sender, chat *telebot.Chat, game *Game) {
+	scheduleTurnTimer(s, chat, game)
+	scheduleBot(s, chat, game)
+}
+
+// revealHint tells the players which chamber the survived pull cleared
+func revealHint(game *Game, result PullResult) string {
+	if game.SpinEach {
+		return fmt.Sprintf("\n🔍 Chamber %d was empty, but the cylinder is spun before every pull.", result.Chamber)
+	}
+	return fmt.Sprintf("\n🔍 Chamber %d was empty.", result.Chamber)
+}
@@ -0,0 +1,501 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+// recordingSender is a Sender that remembers what the game sent instead of
+// talking to Telegram
+type recordingSender struct {
+	mu       sync.Mutex
+	sent     []string      // Texts sent, in order
+	edits    []string      // Texts messages were edited to
+	raw      []string      // Bot API methods called directly
+	payloads []interface{} // Their payloads
+	rawReply string        // Returned by Raw, a success when empty
+	sendErr  error         // Returned by Send when set
+	editErr  error         // Returned by Edit when set
+	lastID   int
+}
+
+func (s *recordingSender) Send(to telebot.Recipient, what interface{}, options ...interface{}) (*telebot.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sendErr != nil {
+		return nil, s.sendErr
+	}
+	s.sent = append(s.sent, fmt.Sprint(what))
+	s.lastID++
+	chat, _ := to.(*telebot.Chat)
+	return &telebot.Message{ID: s.lastID, Chat: chat}, nil
+}
+
+func (s *recordingSender) Edit(message telebot.Editable, what interface{}, options ...interface{}) (*telebot.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.editErr != nil {
+		return nil, s.editErr
+	}
+	s.edits = append(s.edits, fmt.Sprint(what))
+	msg, _ := message.(*telebot.Message)
+	return msg, nil
+}
+
+func (s *recordingSender) Raw(method string, payload interface{}) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.raw = append(s.raw, method)
+	s.payloads = append(s.payloads, payload)
+	if s.rawReply != "" {
+		return []byte(s.rawReply), nil
+	}
+	return []byte(`{"ok":true}`), nil
+}
+
+// sentWith returns the sent texts that contain substr
+func (s *recordingSender) sentWith(substr string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matches []string
+	for _, text := range s.sent {
+		if strings.Contains(text, substr) {
+			matches = append(matches, text)
+		}
+	}
+	return matches
+}
+
+// testChat gives the test empty stats and chat config stores and no rooms, and
+// returns a group chat to play in
+func testChat(t *testing.T) *telebot.Chat {
+	t.Helper()
+	dir := t.TempDir()
+
+	oldStats, oldChats := stats, chats
+	stats = &statsStore{path: filepath.Join(dir, "stats.json"), Chats: make(map[int64]map[string]*PlayerStats)}
+	chats = &chatStore{path: filepath.Join(dir, "chats.json"), Chats: make(map[int64]*ChatConfig)}
+	roomsMu.Lock()
+	oldRooms := rooms
+	rooms = make(map[int64]*room)
+	roomsMu.Unlock()
+
+	t.Cleanup(func() {
+		// Timers of the test's games may still be about to lock their chat
+		roomsMu.Lock()
+		used := rooms
+		rooms = oldRooms
+		roomsMu.Unlock()
+		for _, r := range used {
+			r.mu.Lock()
+			if r.game != nil {
+				r.game.stopTimers()
+			}
+			r.mu.Unlock()
+		}
+		stats, chats = oldStats, oldChats
+	})
+	return &telebot.Chat{ID: -1001, Type: telebot.ChatGroup}
+}
+
+// playChat puts a running game of alice, bob and carol into the chat, see runningGame
+func playChat(t *testing.T, chat *telebot.Chat, cfg GameConfig, rng Randomizer) *Game {
+	t.Helper()
+	g := runningGame(t, cfg, rng)
+	g.Generation = nextGeneration()
+	roomOf(chat.ID).game = g
+	return g
+}
+
+func TestRedeliveredPullPlaysOnce(t *testing.T) {
+	chat := testChat(t)
+	game := playChat(t, chat, defaultConfig(), lastInChamber())
+	s := &recordingSender{}
+
+	// What the /pull handler does with a message
+	deliver := func(m *telebot.Message) {
+		r := lockChat(m.Chat.ID)
+		defer r.unlock()
+		if r.handled.seen(m.ID) {
+			return
+		}
+		game, err := activeGame(m.Chat.ID)
+		if err != nil {
+			t.Errorf("activeGame() = %v", err)
+			return
+		}
+		playPull(s, m.Chat, game, "alice")
+	}
+
+	m := &telebot.Message{ID: 42, Chat: chat, Sender: &telebot.User{ID: 1, Username: "alice"}, Text: "/pull"}
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			deliver(m)
+		}()
+	}
+	wg.Wait()
+
+	if game.PullCount != 1 {
+		t.Errorf("PullCount = %d, want 1", game.PullCount)
+	}
+	if survived := s.sentWith("survives!"); len(survived) != 1 {
+		t.Errorf("sent %d survival messages, want 1: %q", len(survived), survived)
+	}
+
+	// A new message from the same player is a new pull
+	deliver(&telebot.Message{ID: 43, Chat: chat, Sender: m.Sender, Text: "/pull"})
+	if game.PullCount != 2 {
+		t.Errorf("PullCount after a second message = %d, want 2", game.PullCount)
+	}
+}
+
+func TestPlayerStateIsPerChat(t *testing.T) {
+	group := testChat(t)
+	other := &telebot.Chat{ID: group.ID - 1, Type: telebot.ChatGroup}
+	first := playChat(t, group, defaultConfig(), lastInChamber())
+	second := playChat(t, other, defaultConfig(), lastInChamber())
+	s := &recordingSender{}
+
+	playSkip(s, group, first, "alice")
+	if first.Skips["alice"] != defaultSkips-1 || first.CurrentPlayer() != "bob" {
+		t.Fatalf("skip in the first chat left %d skips and %s's turn", first.Skips["alice"], first.CurrentPlayer())
+	}
+	if second.Skips["alice"] != defaultSkips {
+		t.Errorf("alice has %d skips in the other chat, want %d", second.Skips["alice"], defaultSkips)
+	}
+	if second.CurrentPlayer() != "alice" {
+		t.Errorf("turn in the other chat moved to %s", second.CurrentPlayer())
+	}
+
+	playPull(s, other, second, "alice")
+	if second.PullCount != 1 || !second.HasPulledOnTurn {
+		t.Errorf("pull in the other chat didn't count: %d pulls", second.PullCount)
+	}
+	if first.PullCount != 0 || first.HasPulledOnTurn {
+		t.Errorf("pull in the other chat changed the first game: %d pulls", first.PullCount)
+	}
+
+	// alice isn't up in the first chat any more, whatever she does elsewhere
+	if _, err := first.Pull("alice"); !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("Pull(alice) in the first chat = %v, want %v", err, ErrNotYourTurn)
+	}
+}
+
+func TestLiveGameRejectsSupersededGenerations(t *testing.T) {
+	chat := testChat(t)
+	game := playChat(t, chat, defaultConfig(), lastInChamber())
+	seen := game.Generation
+
+	if got, err := liveGame(chat.ID, seen); err != nil || got != game {
+		t.Fatalf("liveGame() of the current generation = %v, %v", got, err)
+	}
+
+	tests := []struct {
+		name    string
+		replace func()
+	}{
+		{"restarted", func() { game.Restart() }},
+		{"recreated", func() { createGame(chat, "bob", "Bob", defaultConfig()) }},
+		{"stopped", func() { roomOf(chat.ID).game = nil }},
+		{"ended", func() { game.IsActive = false }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game = playChat(t, chat, defaultConfig(), lastInChamber())
+			seen = game.Generation
+			tt.replace()
+			if _, err := liveGame(chat.ID, seen); !errors.Is(err, ErrStaleGame) {
+				t.Errorf("liveGame() after the game was %s = %v, want %v", tt.name, err, ErrStaleGame)
+			}
+		})
+	}
+}
+
+func TestCloneInheritsLastSettings(t *testing.T) {
+	chat := testChat(t)
+	cfg := defaultConfig()
+	cfg.Chambers, cfg.Bullets, cfg.SkipsPerPlayer = 8, 2, 3
+	cfg.Reveal, cfg.JamChance, cfg.NoShuffle = true, 15, true
+	s := &recordingSender{}
+
+	last := createGame(chat, "alice", "Alice", cfg)
+	if roomOf(chat.ID).lastConfig != nil {
+		t.Error("a game that never started was kept for /clone")
+	}
+	last.Join("bob", "Bob")
+	// What /mode hardcore does in the lobby
+	if err := last.SetMode(ModeHardcore); err != nil {
+		t.Fatalf("SetMode(hardcore) = %v", err)
+	}
+	startGame(s, chat, last)
+	last.stopTimers()
+	last.IsActive = false
+	roomOf(chat.ID).game = nil
+
+	// What /clone creates the next game from
+	cloned := createGame(chat, "carol", "Carol", *roomOf(chat.ID).lastConfig)
+	want := cfg
+	want.Mode = ModeHardcore
+	if cloned.GameConfig != want {
+		t.Errorf("cloned settings = %+v, want %+v", cloned.GameConfig, want)
+	}
+	if len(cloned.Players) != 1 || cloned.Players[0] != "carol" || cloned.Phase != PhaseLobby {
+		t.Errorf("cloned game has players %v in phase %d, want only its creator in the lobby", cloned.Players, cloned.Phase)
+	}
+	// Hardcore mode only takes the skips away once the clone starts
+	if cloned.Skips["carol"] != 3 {
+		t.Errorf("cloned game gives %d skips, want the configured 3", cloned.Skips["carol"])
+	}
+	cloned.Join("dave", "Dave")
+	startGame(s, chat, cloned)
+	if cloned.SkipsPerPlayer != 0 || cloned.Skips["carol"] != 0 {
+		t.Errorf("started clone gives %d skips, want none in hardcore mode", cloned.Skips["carol"])
+	}
+}
+
+func TestLeaveRacesPull(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     bool
+		wantPulls int
+		wantAlice bool
+	}{
+		// A started game can't be left, so the pull always wins
+		{"running", true, 1, true},
+		// A lobby can't be pulled in, so the leave always wins
+		{"lobby", false, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := testChat(t)
+			game := lobbyGame(t, defaultConfig(), lastInChamber())
+			game.Join("dave", "Dave")
+			if tt.start {
+				game.Start()
+			}
+			game.Generation = nextGeneration()
+			roomOf(chat.ID).game = game
+			s := &recordingSender{}
+
+			// What the /pull and /leave handlers do with their messages
+			act := func(id int, play func(Sender, *telebot.Chat, *Game, string)) {
+				r := lockChat(chat.ID)
+				defer r.unlock()
+				if r.handled.seen(id) {
+					return
+				}
+				game, err := activeGame(chat.ID)
+				if err != nil {
+					s.Send(chat, errorMessage(err, game))
+					return
+				}
+				play(s, chat, game, "alice")
+			}
+			var wg sync.WaitGroup
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				act(1, playPull)
+			}()
+			go func() {
+				defer wg.Done()
+				act(2, playLeave)
+			}()
+			wg.Wait()
+
+			if game.PullCount != tt.wantPulls {
+				t.Errorf("PullCount = %d, want %d", game.PullCount, tt.wantPulls)
+			}
+			if game.HasPlayer("alice") != tt.wantAlice {
+				t.Errorf("alice in the game = %t, want %t: %v", game.HasPlayer("alice"), tt.wantAlice, game.Players)
+			}
+			if game.CurrentPos < 0 || game.CurrentPos >= len(game.Players) {
+				t.Errorf("CurrentPos = %d with %d players", game.CurrentPos, len(game.Players))
+			}
+		})
+	}
+}
+
+func TestNudge(t *testing.T) {
+	chat := testChat(t)
+	s := &recordingSender{}
+
+	lobby := lobbyGame(t, defaultConfig(), lastInChamber())
+	playNudge(s, chat, lobby)
+	if len(s.sentWith("it's your turn!")) != 0 {
+		t.Errorf("a lobby was nudged: %q", s.sent)
+	}
+
+	game := playChat(t, chat, defaultConfig(), lastInChamber())
+	playSkip(s, chat, game, "alice")
+	playNudge(s, chat, game)
+	if pings := s.sentWith("it's your turn!"); len(pings) != 1 || !strings.Contains(pings[0], "bob") {
+		t.Fatalf("nudges sent = %q, want one pinging bob", pings)
+	}
+
+	playNudge(s, chat, game)
+	if pings := s.sentWith("it's your turn!"); len(pings) != 1 {
+		t.Errorf("a second nudge right away was sent: %q", pings)
+	}
+	if len(s.sentWith("They were just nudged!")) != 1 {
+		t.Errorf("the early nudge wasn't refused: %q", s.sent)
+	}
+
+	game.lastNudge = time.Now().Add(-nudgeCooldown)
+	playNudge(s, chat, game)
+	if pings := s.sentWith("it's your turn!"); len(pings) != 2 {
+		t.Errorf("a nudge after the cooldown wasn't sent: %q", pings)
+	}
+}
+
+func TestAssistedPull(t *testing.T) {
+	chat := testChat(t)
+	game := playChat(t, chat, defaultConfig(), lastInChamber())
+	s := &recordingSender{}
+
+	if err := game.CanAssist("alice"); !errors.Is(err, ErrNoConsent) {
+		t.Errorf("CanAssist(alice) without consent = %v, want %v", err, ErrNoConsent)
+	}
+	if err := game.AllowAssist("dave"); !errors.Is(err, ErrNotInGame) {
+		t.Errorf("AllowAssist(dave) = %v, want %v", err, ErrNotInGame)
+	}
+	for _, player := range []string{"alice", "bob"} {
+		if err := game.AllowAssist(player); err != nil {
+			t.Fatalf("AllowAssist(%s) = %v", player, err)
+		}
+	}
+	if err := game.CanAssist("bob"); !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("CanAssist(bob) out of turn = %v, want %v", err, ErrNotYourTurn)
+	}
+	if err := game.CanAssist("alice"); err != nil {
+		t.Fatalf("CanAssist(alice) = %v", err)
+	}
+
+	// What /pullfor @alice does once the checks pass
+	game.assisted = true
+	playPull(s, chat, game, "alice")
+	if game.PullCount != 1 || !game.HasPulledOnTurn || game.CurrentPlayer() != "alice" {
+		t.Fatalf("assisted pull left %d pulls on %s's turn", game.PullCount, game.CurrentPlayer())
+	}
+	playPass(s, chat, game, "alice")
+	playPull(s, chat, game, "bob")
+
+	var pulls []Event
+	for _, e := range game.Events {
+		if e.Kind == EventPull {
+			pulls = append(pulls, e)
+		}
+	}
+	if len(pulls) != 2 {
+		t.Fatalf("logged %d survived pulls, want 2: %+v", len(pulls), game.Events)
+	}
+	if pulls[0].Player != "alice" || !pulls[0].Assisted {
+		t.Errorf("first pull logged as %+v, want alice's and assisted", pulls[0])
+	}
+	if pulls[1].Player != "bob" || pulls[1].Assisted {
+		t.Errorf("second pull logged as %+v, want bob's and unassisted", pulls[1])
+	}
+}
+
+func TestTurnMessagesShowBadges(t *testing.T) {
+	chat := testChat(t)
+	stats.Chats[chat.ID] = map[string]*PlayerStats{
+		"bob":   {GamesPlayed: 3, Wins: 3},
+		"carol": {GamesPlayed: 3, Wins: 1},
+	}
+	game := lobbyGame(t, defaultConfig(), lastInChamber())
+	game.Generation = nextGeneration()
+	roomOf(chat.ID).game = game
+	s := &recordingSender{}
+
+	startGame(s, chat, game)
+	// Ranks are taken once, so a win during the game doesn't change them
+	stats.Chats[chat.ID]["alice"] = &PlayerStats{GamesPlayed: 1, Wins: 9}
+	playSkip(s, chat, game, "alice")
+
+	if got := s.sentWith("Turn order: Alice, bob 🥇, carol 🥈"); len(got) != 1 {
+		t.Errorf("turn order lacks the medals: %q", s.sent)
+	}
+	if got := s.sentWith("bob 🥇"); len(got) < 2 {
+		t.Errorf("bob's turn lacks the gold medal: %q", s.sent)
+	}
+	if got := s.sentWith("Alice 🥇"); len(got) != 0 {
+		t.Errorf("a win after the start changed the medals: %q", got)
+	}
+}
+
+func TestLateJoinAnnouncesOdds(t *testing.T) {
+	chat := testChat(t)
+	cfg := defaultConfig()
+	cfg.LateJoin = true
+	game := playChat(t, chat, cfg, lastInChamber())
+	s := &recordingSender{}
+
+	playPull(s, chat, game, "alice")
+	if err := game.Join("dave", "Dave"); err != nil {
+		t.Fatalf("late Join(dave) = %v", err)
+	}
+	announceJoin(s, chat, game, "dave", "")
+
+	want := "Dave joined the running game and takes the last seat of the round. The cylinder is unchanged: 5 chamber(s) left with 1 bullet(s), so the next pull is 20.0% likely to be fatal.\nTurn order: Alice, bob, carol, Dave"
+	if got := s.sentWith("joined the running game"); len(got) != 1 || got[0] != want {
+		t.Errorf("late join announced as %q, want %q", got, want)
+	}
+	if game.CurrentPlayer() != "alice" || game.PullCount != 1 {
+		t.Errorf("late join moved the turn to %s with %d pull(s)", game.CurrentPlayer(), game.PullCount)
+	}
+
+	closed := playChat(t, chat, defaultConfig(), lastInChamber())
+	if err := closed.Join("dave", "Dave"); !errors.Is(err, ErrGameAlreadyStarted) {
+		t.Errorf("Join() of a running game without late joins = %v, want %v", err, ErrGameAlreadyStarted)
+	}
+}
+
+func TestStopNeedsConfirmation(t *testing.T) {
+	tests := []struct {
+		name    string
+		stops   []bool        // Whether each /stop is a /stop yes
+		armedAt time.Duration // When an earlier /stop asked for confirmation, 0 for never
+		asked   bool
+		stopped bool
+	}{
+		{"single stop", []bool{false}, 0, true, false},
+		{"double stop", []bool{false, false}, 0, true, true},
+		{"stop yes", []bool{true}, 0, false, true},
+		{"second stop too late", []bool{false}, -stopConfirmWindow - time.Second, true, false},
+		{"second stop in time", []bool{false}, -stopConfirmWindow / 2, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := testChat(t)
+			game := playChat(t, chat, defaultConfig(), lastInChamber())
+			if tt.armedAt != 0 {
+				game.stopArmed = time.Now().Add(tt.armedAt)
+			}
+			s := &recordingSender{}
+
+			for _, confirmed := range tt.stops {
+				playStop(s, chat, game, confirmed)
+			}
+			if stopped := roomOf(chat.ID).game == nil; stopped != tt.stopped {
+				t.Fatalf("stopped = %t, want %t: %q", stopped, tt.stopped, s.sent)
+			}
+			if asked := len(s.sentWith("Really stop the game?")) == 1; asked != tt.asked {
+				t.Errorf("confirmation asked = %t, want %t", asked, tt.asked)
+			}
+			if got := len(s.sentWith("Game stopped.")) == 1; got != tt.stopped {
+				t.Errorf("stop announced = %t, want %t", got, tt.stopped)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import "github.com/tucnak/telebot"
+
+// practiceLabel marks every message about a practice game
+const practiceLabel = "🎓 [Practice] "
+
+// practiceSender labels the text messages it sends as practice
+type practiceSender struct {
+	Sender
+}
+
+func (p practiceSender) Send(to telebot.Recipient, what interface{}, options ...interface{}) (*telebot.Message, error) {
+	if text, ok := what.(string); ok {
+		what = practiceLabel + text
+	}
+	return p.Sender.Send(to, what, options...)
+}
+
+func (p practiceSender) Edit(message telebot.Editable, what interface{}, options ...interface{}) (*telebot.Message, error) {
+	if text, ok := what.(string); ok {
+		what = practiceLabel + text
+	}
+	return p.Sender.Edit(message, what, options...)
+}
+
+// senderFor returns the sender to announce the game's events with, labelling
+// them when it is a practice game. Announcements are never deduped.
+func senderFor(s Sender, game *Game) Sender {
+	s = undeduped(s)
+	if _, labelled := s.(practiceSender); labelled || !game.Practice {
+		return s
+	}
+	return practiceSender{s}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPracticeDeathReloads(t *testing.T) {
+	chat := testChat(t)
+	cfg := defaultConfig()
+	cfg.Practice = true
+	// The first cylinder kills alice straight away, the reloaded one holds out
+	game := playChat(t, chat, cfg, &scriptedRandomizer{values: []int{0, defaultChambers - 1}})
+	s := &recordingSender{}
+
+	playPull(s, chat, game, "alice")
+
+	if !game.IsActive || roomOf(chat.ID).game != game {
+		t.Fatal("a practice death ended the game")
+	}
+	if game.PullCount != 0 || !game.Cylinder[defaultChambers-1] {
+		t.Errorf("cylinder wasn't reloaded: %d pulls, %v", game.PullCount, game.Cylinder)
+	}
+	if game.CurrentPlayer() != "bob" || !game.HasPlayer("alice") {
+		t.Errorf("after the practice death it's %s's turn with players %v, want bob's with alice still in", game.CurrentPlayer(), game.Players)
+	}
+	if len(stats.Chats) != 0 {
+		t.Errorf("practice wrote stats: %v", stats.Chats)
+	}
+	for _, text := range s.sent {
+		if !strings.HasPrefix(text, practiceLabel) {
+			t.Errorf("message isn't labelled as practice: %q", text)
+		}
+	}
+	if len(s.sentWith("would be dead")) != 1 {
+		t.Errorf("the practice death wasn't announced: %q", s.sent)
+	}
+}
+
+func TestPracticeGamesKeepNoStats(t *testing.T) {
+	chat := testChat(t)
+	cfg := defaultConfig()
+	cfg.Practice = true
+	game := runningGame(t, cfg, lastInChamber())
+	game.IsActive = false
+
+	stats.recordGame(chat.ID, game, "alice")
+	if len(stats.Chats) != 0 {
+		t.Errorf("a practice game was recorded: %v", stats.Chats)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// preset is a named cylinder and skip setup that /create can start from
+type preset struct {
+	Chambers int
+	Bullets  int
+	Skips    int
+}
+
+var presets = map[string]preset{
+	"classic":  {Chambers: 6, Bullets: 1, Skips: 2},
+	"brutal":   {Chambers: 6, Bullets: 3, Skips: 0},
+	"marathon": {Chambers: 12, Bullets: 1, Skips: 4},
+}
+
+// apply writes the preset's settings into the game config
+func (p preset) apply(cfg *GameConfig) {
+	cfg.Chambers = p.Chambers
+	cfg.Bullets = p.Bullets
+	cfg.SkipsPerPlayer = p.Skips
+}
+
+// presetList renders the available presets for /presets
+func presetList() string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("🎛 Presets for /create:")
+	for _, name := range names {
+		p := presets[name]
+		fmt.Fprintf(&b, "\n%s - %d chambers, %d bullet(s), %d skip(s)", name, p.Chambers, p.Bullets, p.Skips)
+	}
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tucnak/telebot"
+)
+
+// queuedPlayer is a player waiting in line for the next game
+type queuedPlayer struct {
+	ID   string
+	Name string
+}
+
+// enqueue reserves a spot in the chat's next game and reports whether the
+// player was newly added. The caller has the chat locked.
+func enqueue(chatID int64, player queuedPlayer) bool {
+	r := roomOf(chatID)
+	for _, queued := range r.queue {
+		if queued.ID == player.ID {
+			return false
+		}
+	}
+	r.queue = append(r.queue, player)
+	return true
+}
+
+// startQueuedGame opens the chat's next game with everyone who queued, the
+// first in line becoming its creator. The caller has the chat locked.
+func startQueuedGame(s Sender, chat *telebot.Chat) {
+	r := roomOf(chat.ID)
+	queued := r.queue
+	if len(queued) == 0 || draining.Load() {
+		// While draining the queue waits for the bot to take games again
+		return
+	}
+	r.queue = nil
+
+	cfg := chats.get(chat.ID).Defaults
+	cfg.Host = false // Everyone in line wants to play
+	game := createGame(chat, queued[0].ID, queued[0].Name, cfg)
+	for i, player := range queued[1:] {
+		if errors.Is(game.Join(player.ID, player.Name), ErrGameFull) {
+			// Whoever doesn't fit stays in line for the game after
+			r.queue = queued[i+1:]
+			break
+		}
+	}
+
+	s.Send(chat, fmt.Sprintf("🎮 A new game is open for the queued players: %s\nUse /join to join too, and /start when everyone is in.",
+		game.nameList(game.Players)))
+	scheduleJoinWindow(s, chat, game)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestQueuedPlayersJoinTheNextGame(t *testing.T) {
+	chat := testChat(t)
+	game := playChat(t, chat, defaultConfig(), &scriptedRandomizer{})
+	s := &recordingSender{}
+
+	for _, player := range []queuedPlayer{{"dave", "Dave"}, {"erin", "Erin"}} {
+		if !enqueue(chat.ID, player) {
+			t.Fatalf("enqueue(%s) = false, want true", player.ID)
+		}
+	}
+	if enqueue(chat.ID, queuedPlayer{"dave", "Dave"}) {
+		t.Error("dave was queued twice")
+	}
+
+	// The bullet is in the first chamber, so alice ends the game
+	playPull(s, chat, game, "alice")
+
+	r := roomOf(chat.ID)
+	next := r.game
+	if next == nil || next == game {
+		t.Fatal("no new game was opened for the queue")
+	}
+	if want := []string{"dave", "erin"}; !slices.Equal(next.Players, want) {
+		t.Errorf("players of the next game = %v, want %v", next.Players, want)
+	}
+	if next.Creator != "dave" || next.Phase != PhaseLobby {
+		t.Errorf("next game is created by %s in phase %d, want dave in the lobby", next.Creator, next.Phase)
+	}
+	if len(r.queue) != 0 {
+		t.Errorf("queue = %v after opening the game, want it empty", r.queue)
+	}
+	if len(s.sentWith("A new game is open for the queued players")) != 1 {
+		t.Errorf("the new game wasn't announced: %q", s.sent)
+	}
+}
+
+func TestQueueOverflowWaitsForTheGameAfter(t *testing.T) {
+	chat := testChat(t)
+	cfg := defaultConfig()
+	cfg.MaxPlayers = 2
+	chats.Chats[chat.ID] = &ChatConfig{Defaults: cfg}
+
+	for _, id := range []string{"dave", "erin", "frank"} {
+		enqueue(chat.ID, queuedPlayer{id, id})
+	}
+	startQueuedGame(&recordingSender{}, chat)
+
+	r := roomOf(chat.ID)
+	if want := []string{"dave", "erin"}; r.game == nil || !slices.Equal(r.game.Players, want) {
+		t.Fatalf("players of the next game = %v, want %v", r.game, want)
+	}
+	if len(r.queue) != 1 || r.queue[0].ID != "frank" {
+		t.Errorf("queue = %v, want frank still waiting", r.queue)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+)
+
+// Randomizer is the source of every random choice a game makes: bullet
+// placement, jams and bot decisions. Injecting it makes outcomes reproducible.
+type Randomizer interface {
+	Intn(n int) int
+}
+
+// seeder is implemented by randomizers that can replay their sequence from a seed
+type seeder interface {
+	Seed() int64
+}
+
+// seededRandomizer is a deterministic Randomizer. Games get one seeded from
+// crypto/rand, and replaying with the same seed reproduces the game exactly.
+type seededRandomizer struct {
+	r    *rand.Rand
+	seed int64
+}
+
+func newSeededRandomizer(seed int64) *seededRandomizer {
+	return &seededRandomizer{r: rand.New(rand.NewSource(seed)), seed: seed}
+}
+
+func (s *seededRandomizer) Intn(n int) int { return s.r.Intn(n) }
+
+func (s *seededRandomizer) Seed() int64 { return s.seed }
+
+// newRandomizer returns the randomizer for a new game, unpredictably seeded
+func newRandomizer() Randomizer {
+	return newSeededRandomizer(newSeed())
+}
+
+// newSeed draws a seed from the system's secure random source
+func newSeed() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return int64(binary.LittleEndian.Uint64(b[:]))
+}
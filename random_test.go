@@ -0,0 +1,117 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestScriptedRandomizerDrivesGame(t *testing.T) {
+	for chamber := 0; chamber < defaultChambers; chamber++ {
+		g := runningGame(t, defaultConfig(), &scriptedRandomizer{values: []int{chamber}})
+
+		// alice keeps pulling, so the scripted chamber decides when she dies
+		for pull := 0; pull < chamber; pull++ {
+			result, err := g.Pull("alice")
+			if err != nil || result.Dead {
+				t.Fatalf("bullet in chamber %d: pull %d = %+v, %v, want a survival", chamber+1, pull+1, result, err)
+			}
+		}
+		result, err := g.Pull("alice")
+		if err != nil || !result.Dead {
+			t.Fatalf("bullet in chamber %d: pull %d = %+v, %v, want a death", chamber+1, chamber+1, result, err)
+		}
+		if g.IsActive {
+			t.Errorf("bullet in chamber %d: the game is still active after the death", chamber+1)
+		}
+	}
+}
+
+func TestSeededRandomizerReplays(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Chambers, cfg.Bullets = 12, 3
+
+	first := newGame("alice", "Alice", cfg, newSeededRandomizer(7))
+	again := newGame("alice", "Alice", cfg, newSeededRandomizer(7))
+	if !slices.Equal(first.Cylinder, again.Cylinder) {
+		t.Errorf("the same seed loaded %v and %v", first.Cylinder, again.Cylinder)
+	}
+	if first.Seed != 7 {
+		t.Errorf("Seed = %d, want 7", first.Seed)
+	}
+
+	// A game records no seed when its randomizer can't replay
+	if g := newGame("alice", "Alice", cfg, &scriptedRandomizer{}); g.Seed != 0 {
+		t.Errorf("Seed of a scripted game = %d, want 0", g.Seed)
+	}
+}
+
+func TestNewRandomizerIsUniform(t *testing.T) {
+	const draws = 60000
+	rng := newRandomizer()
+	counts := make([]int, defaultChambers)
+	for i := 0; i < draws; i++ {
+		counts[rng.Intn(defaultChambers)]++
+	}
+	for chamber, n := range counts {
+		if share := float64(n) / draws; math.Abs(share-1.0/defaultChambers) > 0.01 {
+			t.Errorf("chamber %d drawn %.2f%% of the time, want about %.2f%%", chamber+1, 100*share, 100.0/defaultChambers)
+		}
+	}
+
+	if newSeed() == newSeed() {
+		t.Error("two games were seeded alike")
+	}
+}
+
+func TestBulletShuffleIsUniform(t *testing.T) {
+	tests := []struct {
+		name      string
+		chambers  int
+		bullets   int
+		safePulls int
+	}{
+		{"one bullet", 6, 1, 0},
+		{"two bullets", 6, 2, 0},
+		{"three of eight", 8, 3, 0},
+		{"after a safe pull", 6, 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const games = 30000
+			cfg := defaultConfig()
+			cfg.Chambers, cfg.Bullets, cfg.SafePulls = tt.chambers, tt.bullets, tt.safePulls
+			g := newGame("alice", "Alice", cfg, newRandomizer())
+
+			counts := make([]int, tt.chambers)
+			for i := 0; i < games; i++ {
+				g.loadCylinder()
+				loaded := 0
+				for chamber, bullet := range g.Cylinder {
+					if bullet {
+						counts[chamber]++
+						loaded++
+					}
+				}
+				if loaded != tt.bullets {
+					t.Fatalf("loaded %d bullet(s), want %d: %v", loaded, tt.bullets, g.Cylinder)
+				}
+			}
+
+			// Every chamber outside the safe ones is loaded equally often
+			want := float64(tt.bullets) / float64(tt.chambers-tt.safePulls)
+			for chamber, n := range counts {
+				share := float64(n) / games
+				if chamber < tt.safePulls {
+					if n != 0 {
+						t.Errorf("safe chamber %d was loaded %d time(s)", chamber+1, n)
+					}
+					continue
+				}
+				if math.Abs(share-want) > 0.015 {
+					t.Errorf("chamber %d loaded in %.1f%% of games, want about %.1f%%", chamber+1, 100*share, 100*want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/tucnak/telebot"
+)
+
+// Reaction settings of a chat, changed with /config reactions
+const (
+	reactionsOff  = "off"
+	reactionsOn   = "on"   // React in addition to the usual messages
+	reactionsOnly = "only" // React instead of announcing survived pulls
+)
+
+const (
+	reactionSurvive = "👍"
+	reactionDeath   = "💀"
+)
+
+// react sets an emoji reaction on the message. telebot has no call for it, so
+// the Bot API is used directly; older servers or chats that disallow
+// reactions make it return an error.
+func react(s Sender, m *telebot.Message, emoji string) error {
+	payload := map[string]interface{}{
+		"chat_id":    m.Chat.ID,
+		"message_id": m.ID,
+		"reaction":   []map[string]string{{"type": "emoji", "emoji": emoji}},
+	}
+	data, err := s.Raw("setMessageReaction", payload)
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Ok          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return err
+	}
+	if !resp.Ok {
+		return fmt.Errorf("setMessageReaction: %s", resp.Description)
+	}
+	return nil
+}
+
+// reactToPull reacts to the message that made the pull, if the chat wants
+// reactions and there was one, and reports whether the reaction was set
+func reactToPull(s Sender, chat *telebot.Chat, trigger *telebot.Message, emoji string) bool {
+	if trigger == nil || chats.get(chat.ID).Reactions == reactionsOff {
+		return false
+	}
+	if err := react(s, trigger, emoji); err != nil {
+		log.Printf("Error reacting in chat %d: %v", chat.ID, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/tucnak/telebot"
+)
+
+func TestPullReactions(t *testing.T) {
+	tests := []struct {
+		name        string
+		reactions   string
+		bullet      int // Chamber index the bullet is loaded into
+		apiReply    string
+		wantEmoji   string
+		wantMessage bool // The survival is also announced
+	}{
+		{"off", reactionsOff, defaultChambers - 1, "", "", true},
+		{"survive", reactionsOn, defaultChambers - 1, "", reactionSurvive, true},
+		{"survive, reaction only", reactionsOnly, defaultChambers - 1, "", reactionSurvive, false},
+		{"death", reactionsOn, 0, "", reactionDeath, false},
+		{"reactions unavailable", reactionsOnly, defaultChambers - 1, `{"ok":false,"description":"method not found"}`, reactionSurvive, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := testChat(t)
+			if err := chats.set(chat.ID, "reactions", tt.reactions); err != nil {
+				t.Fatal(err)
+			}
+			game := playChat(t, chat, defaultConfig(), &scriptedRandomizer{values: []int{tt.bullet}})
+			s := &recordingSender{rawReply: tt.apiReply}
+
+			game.trigger = &telebot.Message{ID: 77, Chat: chat}
+			playPull(s, chat, game, "alice")
+
+			if tt.wantEmoji == "" {
+				if len(s.raw) != 0 {
+					t.Errorf("reacted with reactions off: %v", s.raw)
+				}
+			} else {
+				if len(s.raw) != 1 || s.raw[0] != "setMessageReaction" {
+					t.Fatalf("API calls = %v, want one setMessageReaction", s.raw)
+				}
+				payload := s.payloads[0].(map[string]interface{})
+				emoji := payload["reaction"].([]map[string]string)[0]["emoji"]
+				if emoji != tt.wantEmoji || payload["message_id"] != 77 {
+					t.Errorf("reacted %s to message %v, want %s to 77", emoji, payload["message_id"], tt.wantEmoji)
+				}
+			}
+			if announced := len(s.sentWith("survives!")) == 1; announced != tt.wantMessage {
+				t.Errorf("survival announced = %t, want %t", announced, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+// stopTimers stops every timer the game has running, so none of them fires
+// into a chat whose game is gone
+func (g *Game) stopTimers() {
+	if g.turnTimer != nil {
+		g.turnTimer.Stop()
+		g.turnTimer = nil
+	}
+	stopJoinWindow(g)
+}
+
+// hardReset clears everything the bot keeps about the chat's play: the game,
+// its timers, the queue, a pending duel and the other short-lived state. The
+// leaderboard and the record of past games are kept unless full is set. The
+// caller has the chat locked.
+func hardReset(chatID int64, full bool) error {
+	r := roomOf(chatID)
+	if game := r.game; game != nil {
+		game.stopTimers()
+		// Bot moves still scheduled see the game is over and do nothing
+		game.IsActive = false
+		r.game = nil
+	}
+	if c := r.challenge; c != nil {
+		c.timer.Stop()
+		r.challenge = nil
+	}
+	r.queue = nil
+	r.finished = nil
+	r.graves = nil
+	r.unannounced = ""
+
+	if !full {
+		return nil
+	}
+	r.lastSeed, r.seeded = 0, false
+	r.lastConfig = nil
+	return stats.forget(chatID)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHardReset(t *testing.T) {
+	tests := []struct {
+		name      string
+		full      bool
+		keepStats bool
+	}{
+		{"keeping the leaderboard", false, true},
+		{"full", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := testChat(t)
+			stats.Chats[chat.ID] = map[string]*PlayerStats{"alice": {GamesPlayed: 2, Wins: 1}}
+			s := &recordingSender{}
+
+			r := lockChat(chat.ID)
+			game := lobbyGame(t, defaultConfig(), lastInChamber())
+			game.Generation = nextGeneration()
+			r.game = game
+			r.lastConfig = &game.GameConfig
+			startGame(s, chat, game)
+			turnTimer := game.turnTimer
+			if turnTimer == nil {
+				t.Fatal("the game has no turn timer to stop")
+			}
+			enqueue(chat.ID, queuedPlayer{"dave", "Dave"})
+			duelTimer := time.AfterFunc(time.Hour, func() {})
+			r.challenge = &challenge{Challenger: "erin", Opponent: "frank", timer: duelTimer}
+			r.graves = []*grave{{Player: "carol"}}
+			r.unannounced = "💥 BANG!"
+
+			err := hardReset(chat.ID, tt.full)
+			r.unlock()
+			if err != nil {
+				t.Fatalf("hardReset() = %v", err)
+			}
+
+			r = lockChat(chat.ID)
+			defer r.unlock()
+			if r.game != nil || game.IsActive {
+				t.Error("the game survived the reset")
+			}
+			if turnTimer.Stop() || game.turnTimer != nil {
+				t.Error("the turn timer is still pending")
+			}
+			if duelTimer.Stop() || r.challenge != nil {
+				t.Error("the duel is still pending")
+			}
+			if len(r.queue) != 0 || r.finished != nil || r.graves != nil || r.unannounced != "" {
+				t.Errorf("state left: queue %v, finished %v, graves %v, unannounced %q", r.queue, r.finished, r.graves, r.unannounced)
+			}
+			if kept := stats.get(chat.ID, "alice").GamesPlayed == 2; kept != tt.keepStats {
+				t.Errorf("leaderboard kept = %t, want %t", kept, tt.keepStats)
+			}
+			if kept := r.lastConfig != nil; kept != tt.keepStats {
+				t.Errorf("last settings kept = %t, want %t", kept, tt.keepStats)
+			}
+		})
+	}
+}
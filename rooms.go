@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+// room is everything the bot keeps about the play in one chat. Every chat has
+// a lock of its own, so a busy group never holds up the games of another. The
+// fields are only touched with the chat locked, see lockChat.
+type room struct {
+	mu sync.Mutex
+
+	game        *Game          // The chat's current game
+	finished    *Game          // The last finished game, kept a while so it can still be looked at
+	queue       []queuedPlayer // Players waiting for the next game
+	challenge   *challenge     // The duel waiting to be accepted
+	graves      []*grave       // Players who died in the latest game
+	unannounced string         // End of game announcement that couldn't be delivered, see announceEnd
+	lastSeed    int64          // Seed of the most recently finished game
+	seeded      bool           // A game has finished, so lastSeed is set
+	lastConfig  *GameConfig    // Settings of the most recently started game
+	handled     messageLog
+
+	info *gameInfo // What other chats can see of the game, guarded by roomsMu
+}
+
+// gameInfo is a copy of what other chats may know about a chat's active game,
+// as they can't lock the chat to look at the game itself. It is published each
+// time the chat is unlocked.
+type gameInfo struct {
+	Mode    Mode
+	Players []string
+	Created time.Time
+	Phase   Phase
+	Waiting string          // Name of the player whose turn it is, once running
+	saved   json.RawMessage // The game as the game state file keeps it
+}
+
+var (
+	// roomsMu guards the rooms map and the published gameInfo of each room.
+	// It is only held briefly and never while waiting for a chat's lock.
+	roomsMu sync.Mutex
+	rooms   = make(map[int64]*room)
+)
+
+// roomOf returns the chat's room, making it on first use. Its fields may only
+// be touched once the chat is locked.
+func roomOf(chatID int64) *room {
+	roomsMu.Lock()
+	defer roomsMu.Unlock()
+
+	r, ok := rooms[chatID]
+	if !ok {
+		r = &room{}
+		rooms[chatID] = r
+	}
+	return r
+}
+
+// lockChat locks the chat for a command, timer or bot move and returns its
+// room. Only one chat is ever locked at a time, which rules out deadlocks.
+func lockChat(chatID int64) *room {
+	r := roomOf(chatID)
+	r.mu.Lock()
+	return r
+}
+
+// unlock publishes the room's game to the other chats and unlocks the chat
+func (r *room) unlock() {
+	r.publish()
+	r.mu.Unlock()
+}
+
+// publish records what other chats may know about the room's game. The
+// caller has the chat locked.
+func (r *room) publish() {
+	var info *gameInfo
+	if g := r.game; g != nil && g.IsActive {
+		info = &gameInfo{Mode: g.Mode, Players: slices.Clone(g.Players), Created: g.Created, Phase: g.Phase}
+		if g.Phase == PhaseRunning && len(g.Players) > 0 {
+			info.Waiting = g.name(g.CurrentPlayer())
+		}
+		saved, err := json.Marshal(g)
+		if err != nil {
+			log.Printf("Error saving a game: %v", err)
+		}
+		info.saved = saved
+	}
+
+	roomsMu.Lock()
+	r.info = info
+	roomsMu.Unlock()
+}
+
+// publishedGames returns the published info of every chat with an active game
+func publishedGames() map[int64]*gameInfo {
+	roomsMu.Lock()
+	defer roomsMu.Unlock()
+
+	infos := make(map[int64]*gameInfo)
+	for chatID, r := range rooms {
+		if r.info != nil {
+			infos[chatID] = r.info
+		}
+	}
+	return infos
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+const defaultDedupeWindow = time.Second
+
+// Sender is the part of the bot API the game uses to talk to chats
+type Sender interface {
+	Send(to telebot.Recipient, what interface{}, options ...interface{}) (*telebot.Message, error)
+	Edit(message telebot.Editable, what interface{}, options ...interface{}) (*telebot.Message, error)
+	Raw(method string, payload interface{}) ([]byte, error)
+}
+
+// dedupingSender drops a text message identical to the one just sent to the
+// same chat, which happens when retries or races produce the same reply twice.
+// Only back-to-back duplicates inside the window are dropped, so a message that
+// is legitimately repeated later still goes out. Game announcements bypass it
+// through undeduped, since two quick pulls can rightly read the same.
+type dedupingSender struct {
+	Sender
+	window time.Duration
+
+	mu   sync.Mutex
+	last map[string]sentMessage
+}
+
+type sentMessage struct {
+	hash uint64
+	at   time.Time
+}
+
+func newDedupingSender(s Sender, window time.Duration) *dedupingSender {
+	return &dedupingSender{Sender: s, window: window, last: make(map[string]sentMessage)}
+}
+
+func (d *dedupingSender) Send(to telebot.Recipient, what interface{}, options ...interface{}) (*telebot.Message, error) {
+	if text, ok := what.(string); ok && d.window > 0 && d.duplicate(to.Recipient(), text, options) {
+		log.Printf("Suppressed duplicate message to %s", to.Recipient())
+		return nil, nil
+	}
+	return d.Sender.Send(to, what, options...)
+}
+
+// duplicate reports whether text with the same options, reply markup included,
+// was the last message sent to the chat within the window, and remembers it as
+// the last message otherwise
+func (d *dedupingSender) duplicate(chat, text string, options []interface{}) bool {
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	if len(options) > 0 {
+		markup, err := json.Marshal(options)
+		if err != nil {
+			return false
+		}
+		h.Write(markup)
+	}
+	sum := h.Sum64()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if prev, ok := d.last[chat]; ok && prev.hash == sum && now.Sub(prev.at) < d.window {
+		return true
+	}
+	d.last[chat] = sentMessage{hash: sum, at: now}
+	return false
+}
+
+// undeduped returns the sender underneath any deduping, for messages that
+// must go out even when they repeat the last one
+func undeduped(s Sender) Sender {
+	if d, ok := s.(*dedupingSender); ok {
+		return d.Sender
+	}
+	return s
+}
+
+// dedupeWindow parses SEND_DEDUPE_WINDOW as a duration, "0" disabling deduping
+func dedupeWindow(value string) time.Duration {
+	if value == "" {
+		return defaultDedupeWindow
+	}
+	window, err := time.ParseDuration(value)
+	if err != nil || window < 0 {
+		log.Printf("Invalid SEND_DEDUPE_WINDOW %q, using %v", value, defaultDedupeWindow)
+		return defaultDedupeWindow
+	}
+	return window
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+const (
+	defaultPollerTimeout = 10 * time.Second
+	maxPollerTimeout     = 60 * time.Second
+)
+
+// botSettings builds the telebot settings from the token and environment
+func botSettings(token string) telebot.Settings {
+	if url := os.Getenv("TELEGRAM_API_URL"); url != "" {
+		// The telebot version in use always talks to api.telegram.org and has
+		// no setting for another server, so say so rather than silently ignore it
+		log.Printf("TELEGRAM_API_URL=%s is not supported by this telebot version, using the official API", url)
+	}
+	return telebot.Settings{
+		Token:  token,
+		Poller: &telebot.LongPoller{Timeout: pollerTimeout(os.Getenv("POLLER_TIMEOUT_SECONDS"))},
+	}
+}
+
+// pollerTimeout parses POLLER_TIMEOUT_SECONDS, falling back to the default for
+// invalid values and clamping values above the maximum
+func pollerTimeout(value string) time.Duration {
+	if value == "" {
+		return defaultPollerTimeout
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		log.Printf("Invalid POLLER_TIMEOUT_SECONDS %q, using %v", value, defaultPollerTimeout)
+		return defaultPollerTimeout
+	}
+
+	timeout := time.Duration(seconds) * time.Second
+	if timeout > maxPollerTimeout {
+		log.Printf("POLLER_TIMEOUT_SECONDS %d is too large, using %v", seconds, maxPollerTimeout)
+		return maxPollerTimeout
+	}
+	return timeout
+}
+
+// secret reads a secret following the Docker secrets convention: the file
+// named by NAME_FILE wins, otherwise the NAME environment variable is used
+func secret(name string) (string, error) {
+	if path := os.Getenv(name + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading %s_FILE: %w", name, err)
+		}
+		value := strings.TrimSpace(string(data))
+		if value == "" {
+			return "", fmt.Errorf("%s_FILE %s is empty", name, path)
+		}
+		return value, nil
+	}
+
+	if value := os.Getenv(name); value != "" {
+		return value, nil
+	}
+	return "", fmt.Errorf("neither %s nor %s_FILE is set", name, name)
+}
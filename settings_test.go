@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+func TestPollerTimeout(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", defaultPollerTimeout},
+		{"30", 30 * time.Second},
+		{"1", time.Second},
+		{"60", maxPollerTimeout},
+		{"61", maxPollerTimeout},
+		{"3600", maxPollerTimeout},
+		{"0", defaultPollerTimeout},
+		{"-5", defaultPollerTimeout},
+		{"ten", defaultPollerTimeout},
+		{"2.5", defaultPollerTimeout},
+	}
+	for _, tt := range tests {
+		if got := pollerTimeout(tt.value); got != tt.want {
+			t.Errorf("pollerTimeout(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestBotSettings(t *testing.T) {
+	t.Setenv("POLLER_TIMEOUT_SECONDS", "25")
+	t.Setenv("TELEGRAM_API_URL", "")
+
+	settings := botSettings("token")
+	if settings.Token != "token" {
+		t.Errorf("Token = %q, want token", settings.Token)
+	}
+	poller, ok := settings.Poller.(*telebot.LongPoller)
+	if !ok {
+		t.Fatalf("Poller is a %T, want a long poller", settings.Poller)
+	}
+	if poller.Timeout != 25*time.Second {
+		t.Errorf("poller timeout = %v, want 25s", poller.Timeout)
+	}
+}
+
+func TestBotSettingsAPIURL(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	tests := []struct {
+		url    string
+		logged bool
+	}{
+		{"", false},
+		{"http://localhost:8081", true},
+	}
+	for _, tt := range tests {
+		logged.Reset()
+		t.Setenv("TELEGRAM_API_URL", tt.url)
+
+		// telebot v2.0.0 has no setting for the server, so the URL can only
+		// be reported, never silently dropped
+		settings := botSettings("token")
+		if settings.Token != "token" || settings.Poller == nil {
+			t.Errorf("settings with TELEGRAM_API_URL=%q = %+v", tt.url, settings)
+		}
+		if got := strings.Contains(logged.String(), "TELEGRAM_API_URL"); got != tt.logged {
+			t.Errorf("TELEGRAM_API_URL=%q logged = %t, want %t: %q", tt.url, got, tt.logged, logged.String())
+		}
+	}
+}
+
+func TestSecret(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(t.TempDir(), "empty")
+	if err := os.WriteFile(empty, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     string
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{"environment", "from-env", "", "from-env", false},
+		{"file wins", "from-env", file, "from-file", false},
+		{"empty file", "", empty, "", true},
+		{"missing file", "", filepath.Join(t.TempDir(), "missing"), "", true},
+		{"unset", "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_SECRET", tt.env)
+			t.Setenv("TEST_SECRET_FILE", tt.file)
+
+			got, err := secret("TEST_SECRET")
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("secret() = %q, %v, want %q, error %t", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
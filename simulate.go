@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	maxSimulatedGames   = 100000
+	defaultSimPlayers   = 4
+	maxSimulatedPlayers = 10
+)
+
+// simulation is the outcome of many headless games
+type simulation struct {
+	Games  int
+	Deaths []int // Deaths per seat in the turn order
+	Pulls  int   // Pulls over every game, the fatal ones included
+}
+
+// simulate plays n games of players seats with cfg, every player pulling once
+// and passing, and tallies who died. It only uses the game logic and rng, so
+// it never needs a chat to be locked.
+func simulate(cfg GameConfig, players, n int, rng Randomizer) simulation {
+	cfg.Practice = false // Practice games never end
+	sim := simulation{Deaths: make([]int, players)}
+
+	for i := 0; i < n; i++ {
+		g := newGame("seat1", "seat 1", cfg, rng)
+		for seat := 2; seat <= players; seat++ {
+			g.Join(fmt.Sprintf("seat%d", seat), fmt.Sprintf("seat %d", seat))
+		}
+		if g.Start() != nil {
+			continue
+		}
+
+		for g.IsActive {
+			seat, player := g.CurrentPos, g.CurrentPlayer()
+			result, err := g.Pull(player)
+			if err != nil {
+				break
+			}
+			sim.Pulls++
+			if result.Dead {
+				sim.Deaths[seat]++
+				break
+			}
+			g.Pass(player)
+		}
+		sim.Games++
+	}
+	return sim
+}
+
+// String reports the share of deaths per seat next to what a fair game would
+// give, and how long games lasted
+func (sim simulation) String() string {
+	if sim.Games == 0 {
+		return "🧪 No games could be simulated with these settings."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🧪 %d simulated games with %d players, each pulling once per turn\n", sim.Games, len(sim.Deaths))
+	fmt.Fprintf(&b, "Deaths per seat (a fair share would be %.1f%%):", 100/float64(len(sim.Deaths)))
+	for seat, deaths := range sim.Deaths {
+		fmt.Fprintf(&b, "\nSeat %d: %.1f%%", seat+1, 100*float64(deaths)/float64(sim.Games))
+	}
+	fmt.Fprintf(&b, "\nAverage pulls per game: %.2f", float64(sim.Pulls)/float64(sim.Games))
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSimulateScripted(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.NoShuffle = true
+
+	// One game with the bullet in each chamber: with 6 chambers and 3 seats
+	// every seat takes the bullet twice
+	totals := simulation{Deaths: make([]int, 3)}
+	for chamber := 0; chamber < defaultChambers; chamber++ {
+		sim := simulate(cfg, 3, 1, &scriptedRandomizer{values: []int{chamber}})
+		if sim.Games != 1 || sim.Pulls != chamber+1 || sim.Deaths[chamber%3] != 1 {
+			t.Fatalf("bullet in chamber %d: %+v, want seat %d dying on pull %d", chamber+1, sim, chamber%3+1, chamber+1)
+		}
+		totals.Games += sim.Games
+		totals.Pulls += sim.Pulls
+		for seat, deaths := range sim.Deaths {
+			totals.Deaths[seat] += deaths
+		}
+	}
+	for seat, deaths := range totals.Deaths {
+		if deaths != 2 {
+			t.Errorf("seat %d died %d time(s), want 2", seat+1, deaths)
+		}
+	}
+
+	report := totals.String()
+	for _, want := range []string{"6 simulated games with 3 players", "fair share would be 33.3%", "Seat 1: 33.3%", "Average pulls per game: 3.50"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestSimulateIsRoughlyUniform(t *testing.T) {
+	const games = 30000
+	tests := []struct {
+		name string
+		cfg  func(cfg *GameConfig)
+	}{
+		{"fixed seats", func(cfg *GameConfig) { cfg.NoShuffle = true }},
+		{"shuffled seats", func(cfg *GameConfig) {}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			tt.cfg(&cfg)
+			sim := simulate(cfg, 3, games, newSeededRandomizer(1))
+			if sim.Games != games {
+				t.Fatalf("simulated %d games, want %d", sim.Games, games)
+			}
+
+			// The bullet is equally likely in any chamber and 3 seats share 6 chambers
+			for seat, deaths := range sim.Deaths {
+				if share := float64(deaths) / games; math.Abs(share-1.0/3) > 0.02 {
+					t.Errorf("seat %d died in %.1f%% of games, want about 33.3%%", seat+1, 100*share)
+				}
+			}
+			if avg := float64(sim.Pulls) / games; math.Abs(avg-3.5) > 0.1 {
+				t.Errorf("average pulls per game = %.2f, want about 3.5", avg)
+			}
+		})
+	}
+}
+
+func TestSimulateWithNoGames(t *testing.T) {
+	cfg := defaultConfig()
+	sim := simulate(cfg, 1, 10, newSeededRandomizer(1))
+	if sim.Games != 0 {
+		t.Errorf("simulated %d single player games", sim.Games)
+	}
+	if got := sim.String(); !strings.Contains(got, "No games could be simulated") {
+		t.Errorf("report of no games = %q", got)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/joho/godotenv"
+	"github.com/tucnak/telebot"
+)
+
+// loadDotEnv loads .env when there is one. Production passes its environment
+// directly, so a missing file is expected and not worth logging.
+func loadDotEnv() {
+	if _, err := os.Stat(".env"); errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Error loading .env file: %v", err)
+	}
+}
+
+// selfCheck verifies the bot is usable before it starts polling: the token was
+// accepted by Telegram and every file the bot persists to can be written
+func selfCheck(bot *telebot.Bot, files ...string) error {
+	if bot.Me == nil || bot.Me.Username == "" {
+		return errors.New("telegram did not return the bot's identity, check TELEGRAM_BOT_TOKEN")
+	}
+	log.Printf("✔ Authorized as @%s", bot.Me.Username)
+
+	for _, file := range files {
+		if err := checkWritable(filepath.Dir(file)); err != nil {
+			return fmt.Errorf("%s can't be written: %w", file, err)
+		}
+		log.Printf("✔ %s is writable", file)
+	}
+	return nil
+}
+
+// checkWritable creates dir if needed and proves a file can be written in it
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".selfcheck-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
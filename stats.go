@@ -0,0 +1,438 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const defaultStatsFile = "data/stats.json"
+
+// PlayerStats is the long-term record of a player within one chat
+type PlayerStats struct {
+	Name          string `json:",omitempty"` // How the player was last shown
+	GamesPlayed   int
+	Wins          int
+	Deaths        int
+	EarlyDeaths   int // Deaths before every player had taken a turn
+	WentFirst     int
+	Streak        int // Current run of games survived
+	BestStreak    int
+	Clutch        int    // Bonus points for surviving pulls at high fatal odds
+	SurvivedPulls int    // Pulls that clicked
+	SkipsUsed     int    // Skips used up, jammed ones included
+	Recent        []bool `json:",omitempty"` // Outcomes of the latest games, oldest first, true for a win
+}
+
+// recentGames is how many game outcomes are kept for the trend
+const recentGames = 10
+
+// addRecent remembers the outcome of a game, forgetting the oldest beyond recentGames
+func (ps *PlayerStats) addRecent(won bool) {
+	ps.Recent = append(ps.Recent, won)
+	if len(ps.Recent) > recentGames {
+		ps.Recent = ps.Recent[len(ps.Recent)-recentGames:]
+	}
+}
+
+// sparkline draws game outcomes as a row of ticks, high for a win and low for a loss
+func sparkline(outcomes []bool) string {
+	var b strings.Builder
+	for _, won := range outcomes {
+		if won {
+			b.WriteRune('▇')
+		} else {
+			b.WriteRune('▁')
+		}
+	}
+	return b.String()
+}
+
+// clutchBonus is the bonus for surviving a pull at odds percent fatal odds when
+// pulls from threshold percent count as clutch: a point per 10% faced, so a
+// 50% survival is worth 5. A threshold of 0 turns the bonus off.
+func clutchBonus(odds float64, threshold int) int {
+	if threshold == 0 || odds < float64(threshold) {
+		return 0
+	}
+	return int(odds / 10)
+}
+
+// WinRate returns the percentage of games the player survived
+func (ps PlayerStats) WinRate() float64 {
+	if ps.GamesPlayed == 0 {
+		return 0
+	}
+	return 100 * float64(ps.Wins) / float64(ps.GamesPlayed)
+}
+
+// DeathRate returns the percentage of games the player died in
+func (ps PlayerStats) DeathRate() float64 {
+	if ps.GamesPlayed == 0 {
+		return 0
+	}
+	return 100 * float64(ps.Deaths) / float64(ps.GamesPlayed)
+}
+
+// statsStore keeps player stats per chat and persists them as JSON.
+// It is shared by every chat, so its methods lock it themselves.
+type statsStore struct {
+	mu    sync.Mutex
+	path  string
+	Chats map[int64]map[string]*PlayerStats
+}
+
+// loadStats reads the stats file at path. A missing file yields an empty store.
+func loadStats(path string) (*statsStore, error) {
+	s := &statsStore{path: path, Chats: make(map[int64]map[string]*PlayerStats)}
+	return s, readJSONFile(path, &s.Chats)
+}
+
+// save writes the store to disk
+func (s *statsStore) save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeJSONFile(s.path, s.Chats)
+}
+
+// forget drops the chat's stats and saves the store
+func (s *statsStore) forget(chatID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.Chats, chatID)
+	return writeJSONFile(s.path, s.Chats)
+}
+
+// get returns a copy of the player's stats in the chat, zero if never seen
+func (s *statsStore) get(chatID int64, player string) PlayerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ps, ok := s.Chats[chatID][player]; ok {
+		return *ps
+	}
+	return PlayerStats{}
+}
+
+// entry returns the player's stats in the chat to update, making them if
+// needed. The caller holds s.mu.
+func (s *statsStore) entry(chatID int64, player string) *PlayerStats {
+	chat, ok := s.Chats[chatID]
+	if !ok {
+		chat = make(map[string]*PlayerStats)
+		s.Chats[chatID] = chat
+	}
+	ps, ok := chat[player]
+	if !ok {
+		ps = &PlayerStats{}
+		chat[player] = ps
+	}
+	return ps
+}
+
+// playerEntry is entry for a player of g, who is remembered by the name the
+// game shows. Stats kept under a player's username, from before players were
+// keyed by their Telegram ID, are moved over to it. The caller holds s.mu.
+func (s *statsStore) playerEntry(chatID int64, g *Game, player string) *PlayerStats {
+	name := g.Names[player]
+	if username, ok := strings.CutPrefix(name, "@"); ok {
+		chat := s.Chats[chatID]
+		if old, ok := chat[username]; ok && chat[player] == nil {
+			chat[player] = old
+			delete(chat, username)
+		}
+	}
+	ps := s.entry(chatID, player)
+	if name != "" {
+		ps.Name = name
+	}
+	return ps
+}
+
+// player returns who "@mention" is in the chat's stats, going by the name they
+// were last shown by. A mention that matches nobody is returned as it is.
+func (s *statsStore) player(chatID int64, mention string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for player, ps := range s.Chats[chatID] {
+		if strings.EqualFold(ps.Name, "@"+mention) {
+			return player
+		}
+	}
+	return mention
+}
+
+// aggregate adds up the player's stats over every chat and returns them with
+// the number of chats they have played in
+func (s *statsStore) aggregate(player string) (PlayerStats, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var total PlayerStats
+	played := 0
+	for _, chat := range s.Chats {
+		ps, ok := chat[player]
+		if !ok {
+			continue
+		}
+		played++
+		total.GamesPlayed += ps.GamesPlayed
+		total.Wins += ps.Wins
+		total.Deaths += ps.Deaths
+		total.EarlyDeaths += ps.EarlyDeaths
+		total.WentFirst += ps.WentFirst
+		total.BestStreak = max(total.BestStreak, ps.BestStreak)
+		total.Clutch += ps.Clutch
+		total.SurvivedPulls += ps.SurvivedPulls
+		total.SkipsUsed += ps.SkipsUsed
+	}
+	return total, played
+}
+
+// recordClutch awards the player the clutch bonus for a survived pull taken at
+// odds percent fatal odds and returns it. Bots and practice games earn nothing.
+func (s *statsStore) recordClutch(chatID int64, g *Game, player string, odds float64) int {
+	if _, isBot := g.Bots[player]; isBot || g.Practice {
+		return 0
+	}
+	bonus := clutchBonus(odds, g.ClutchOdds)
+	if bonus > 0 {
+		s.mu.Lock()
+		s.playerEntry(chatID, g, player).Clutch += bonus
+		s.mu.Unlock()
+	}
+	return bonus
+}
+
+// recordGame updates every player's stats once a game has ended with the death of dead
+func (s *statsStore) recordGame(chatID int64, g *Game, dead ...string) {
+	if g.Practice {
+		return
+	}
+	early := !g.FullRound
+	died := make(map[string]bool, len(dead))
+	for _, player := range dead {
+		died[player] = true
+	}
+	pulls, skips := make(map[string]int), make(map[string]int)
+	for _, e := range g.Events {
+		switch e.Kind {
+		case EventPull:
+			pulls[e.Player]++
+		case EventSkip:
+			skips[e.Player]++
+		case EventJam:
+			if !g.JamRefund {
+				skips[e.Player]++
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	first := firstPlayer(g)
+	for _, player := range g.roster() {
+		if _, isBot := g.Bots[player]; isBot {
+			continue
+		}
+		ps := s.playerEntry(chatID, g, player)
+		ps.GamesPlayed++
+		ps.SurvivedPulls += pulls[player]
+		ps.SkipsUsed += skips[player]
+		if player == first {
+			ps.WentFirst++
+		}
+		ps.addRecent(!died[player])
+		if !died[player] {
+			ps.Wins++
+			ps.Streak++
+			if ps.Streak > ps.BestStreak {
+				ps.BestStreak = ps.Streak
+			}
+			continue
+		}
+		ps.Deaths++
+		ps.Streak = 0
+		if early {
+			ps.EarlyDeaths++
+		}
+	}
+}
+
+// firstPlayer returns who had the first turn of the game
+func firstPlayer(g *Game) string {
+	for _, e := range g.Events {
+		switch e.Kind {
+		case EventPull, EventDeath, EventSkip, EventJam:
+			return e.Player
+		}
+	}
+	if len(g.Players) == 0 {
+		return ""
+	}
+	return g.Players[0]
+}
+
+// parseMentions extracts exactly n "@name" mentions from a command payload
+func parseMentions(payload string, n int) ([]string, error) {
+	fields := strings.Fields(payload)
+	if len(fields) != n {
+		return nil, fmt.Errorf("expected %d mentions, got %d", n, len(fields))
+	}
+
+	names := make([]string, n)
+	for i, field := range fields {
+		if len(field) < 2 || field[0] != '@' {
+			return nil, fmt.Errorf("%q is not a mention", field)
+		}
+		names[i] = field[1:]
+	}
+	return names, nil
+}
+
+// ranking returns the players of the chat's leaderboard, best players first.
+// The caller holds s.mu.
+func (s *statsStore) ranking(chatID int64) []string {
+	chat := s.Chats[chatID]
+	players := make([]string, 0, len(chat))
+	for player := range chat {
+		players = append(players, player)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		a, b := chat[players[i]], chat[players[j]]
+		if a.Wins != b.Wins {
+			return a.Wins > b.Wins
+		}
+		return players[i] < players[j]
+	})
+	return players
+}
+
+// medals are the badges of the top of the leaderboard, best first
+var medals = []string{"🥇", "🥈", "🥉"}
+
+// badges returns the medals of the chat's top players. Only players with a win
+// get one, so a chat that is new to the game hands out no medals yet.
+func (s *statsStore) badges(chatID int64) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	badges := make(map[string]string, len(medals))
+	for i, player := range s.ranking(chatID) {
+		if i == len(medals) || s.Chats[chatID][player].Wins == 0 {
+			break
+		}
+		badges[player] = medals[i]
+	}
+	return badges
+}
+
+// leaderboardCSV renders the chat's stats as CSV, best players first, and
+// reports how many players it has. A chat without stats yields only the header.
+func (s *statsStore) leaderboardCSV(chatID int64) ([]byte, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chat := s.Chats[chatID]
+	players := s.ranking(chatID)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"player", "games", "wins", "deaths", "win_rate", "clutch"})
+	for _, player := range players {
+		ps := chat[player]
+		name := ps.Name
+		if name == "" {
+			name = player
+		}
+		w.Write([]string{
+			csvCell(name),
+			strconv.Itoa(ps.GamesPlayed),
+			strconv.Itoa(ps.Wins),
+			strconv.Itoa(ps.Deaths),
+			strconv.FormatFloat(ps.WinRate(), 'f', 1, 64),
+			strconv.Itoa(ps.Clutch),
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), len(players)
+}
+
+// csvCell makes a user chosen value safe to open in a spreadsheet, which would
+// run a cell starting with =, +, - or @ as a formula
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// formatPlayerStats renders the stats of the player shown as name in a chat
+// with their recent trend
+func formatPlayerStats(name string, ps PlayerStats) string {
+	if ps.GamesPlayed == 0 {
+		return fmt.Sprintf("%s hasn't played here yet.", name)
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "📊 Stats of %s\n", name)
+	fmt.Fprintf(&out, "Games: %d\n", ps.GamesPlayed)
+	fmt.Fprintf(&out, "Wins: %d (%.1f%%)\n", ps.Wins, ps.WinRate())
+	fmt.Fprintf(&out, "Deaths: %d (%.1f%%)\n", ps.Deaths, ps.DeathRate())
+	fmt.Fprintf(&out, "Survived pulls: %d\n", ps.SurvivedPulls)
+	fmt.Fprintf(&out, "Skips used: %d\n", ps.SkipsUsed)
+	fmt.Fprintf(&out, "Current streak: %d, longest: %d\n", ps.Streak, ps.BestStreak)
+	fmt.Fprintf(&out, "Clutch points: %d", ps.Clutch)
+	if len(ps.Recent) > 0 {
+		fmt.Fprintf(&out, "\nLast %d game(s): %s", len(ps.Recent), sparkline(ps.Recent))
+	}
+	return out.String()
+}
+
+// formatOwnStats renders a player's stats aggregated over every chat
+func formatOwnStats(ps PlayerStats, chats int) string {
+	if ps.GamesPlayed == 0 {
+		return "You haven't played any games yet! Join one in a group with /join."
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "📊 Your stats across %d chat(s)\n", chats)
+	fmt.Fprintf(&out, "Games: %d\n", ps.GamesPlayed)
+	fmt.Fprintf(&out, "Wins: %d\n", ps.Wins)
+	fmt.Fprintf(&out, "Win rate: %.1f%%\n", ps.WinRate())
+	fmt.Fprintf(&out, "Deaths: %d (%.1f%%, %d before everyone had a turn)\n", ps.Deaths, ps.DeathRate(), ps.EarlyDeaths)
+	fmt.Fprintf(&out, "Survived pulls: %d\n", ps.SurvivedPulls)
+	fmt.Fprintf(&out, "Skips used: %d\n", ps.SkipsUsed)
+	fmt.Fprintf(&out, "Went first: %d\n", ps.WentFirst)
+	fmt.Fprintf(&out, "Longest streak: %d\n", ps.BestStreak)
+	fmt.Fprintf(&out, "Clutch points: %d", ps.Clutch)
+	return out.String()
+}
+
+// formatComparison renders the stats of the players shown as a and b side by side
+func formatComparison(a, b string, sa, sb PlayerStats) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "📊 %s vs %s\n", a, b)
+	fmt.Fprintf(&out, "Games: %d | %d\n", sa.GamesPlayed, sb.GamesPlayed)
+	fmt.Fprintf(&out, "Wins: %d | %d\n", sa.Wins, sb.Wins)
+	fmt.Fprintf(&out, "Win rate: %.1f%% | %.1f%%\n", sa.WinRate(), sb.WinRate())
+	fmt.Fprintf(&out, "Deaths: %d | %d\n", sa.Deaths, sb.Deaths)
+	fmt.Fprintf(&out, "Longest streak: %d | %d\n", sa.BestStreak, sb.BestStreak)
+	fmt.Fprintf(&out, "Clutch points: %d | %d", sa.Clutch, sb.Clutch)
+
+	for _, p := range []struct {
+		name  string
+		stats PlayerStats
+	}{{a, sa}, {b, sb}} {
+		if p.stats.GamesPlayed == 0 {
+			fmt.Fprintf(&out, "\n%s hasn't played here yet.", p.name)
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		payload string
+		want    []string
+		wantErr bool
+	}{
+		{"@alice @bob", []string{"alice", "bob"}, false},
+		{"  @alice   @bob ", []string{"alice", "bob"}, false},
+		{"@alice", nil, true},
+		{"@alice @bob @carol", nil, true},
+		{"alice @bob", nil, true},
+		{"@ @bob", nil, true},
+		{"", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseMentions(tt.payload, 2)
+		if (err != nil) != tt.wantErr || strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("parseMentions(%q, 2) = %v, %v, want %v, error %t", tt.payload, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestFormatComparison(t *testing.T) {
+	alice := PlayerStats{GamesPlayed: 4, Wins: 3, Deaths: 1, BestStreak: 2, Clutch: 5}
+	bob := PlayerStats{GamesPlayed: 2, Wins: 0, Deaths: 2}
+
+	tests := []struct {
+		name    string
+		a, b    PlayerStats
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "both played",
+			a:    alice,
+			b:    bob,
+			want: []string{
+				"📊 @alice vs @bob",
+				"Games: 4 | 2",
+				"Wins: 3 | 0",
+				"Win rate: 75.0% | 0.0%",
+				"Deaths: 1 | 2",
+				"Longest streak: 2 | 0",
+				"Clutch points: 5 | 0",
+			},
+			notWant: []string{"hasn't played"},
+		},
+		{
+			name:    "no history",
+			a:       alice,
+			b:       PlayerStats{},
+			want:    []string{"Games: 4 | 0", "Win rate: 75.0% | 0.0%", "@bob hasn't played here yet."},
+			notWant: []string{"@alice hasn't played"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatComparison("@alice", "@bob", tt.a, tt.b)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("comparison lacks %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("comparison has %q:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregateAcrossChats(t *testing.T) {
+	s := &statsStore{Chats: map[int64]map[string]*PlayerStats{
+		-1: {
+			"alice": {GamesPlayed: 3, Wins: 2, Deaths: 1, BestStreak: 2, Clutch: 4},
+			"bob":   {GamesPlayed: 1, Deaths: 1},
+		},
+		-2: {"alice": {GamesPlayed: 5, Wins: 1, Deaths: 4, EarlyDeaths: 2, BestStreak: 1, WentFirst: 3}},
+		-3: {"carol": {GamesPlayed: 2, Wins: 2}},
+	}}
+
+	tests := []struct {
+		player    string
+		want      PlayerStats
+		wantChats int
+	}{
+		{"alice", PlayerStats{GamesPlayed: 8, Wins: 3, Deaths: 5, EarlyDeaths: 2, WentFirst: 3, BestStreak: 2, Clutch: 4}, 2},
+		{"bob", PlayerStats{GamesPlayed: 1, Deaths: 1}, 1},
+		{"nobody", PlayerStats{}, 0},
+	}
+	for _, tt := range tests {
+		got, played := s.aggregate(tt.player)
+		if played != tt.wantChats || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("aggregate(%s) = %+v in %d chat(s), want %+v in %d", tt.player, got, played, tt.want, tt.wantChats)
+		}
+	}
+}
+
+func TestFormatOwnStats(t *testing.T) {
+	if got := formatOwnStats(PlayerStats{}, 0); !strings.Contains(got, "You haven't played any games yet!") {
+		t.Errorf("stats of a newcomer = %q", got)
+	}
+
+	got := formatOwnStats(PlayerStats{GamesPlayed: 8, Wins: 3, Deaths: 5, EarlyDeaths: 2}, 2)
+	for _, want := range []string{"across 2 chat(s)", "Games: 8", "Win rate: 37.5%", "Deaths: 5 (62.5%, 2 before everyone had a turn)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("own stats lack %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestLeaderboardCSV(t *testing.T) {
+	s := &statsStore{Chats: map[int64]map[string]*PlayerStats{
+		-1: {
+			"bob":   {GamesPlayed: 4, Wins: 1, Deaths: 3},
+			"alice": {GamesPlayed: 4, Wins: 3, Deaths: 1, Clutch: 2},
+			"carol": {GamesPlayed: 3, Wins: 1, Deaths: 2},
+		},
+	}}
+
+	tests := []struct {
+		name        string
+		chatID      int64
+		want        string
+		wantPlayers int
+	}{
+		{
+			name:   "played",
+			chatID: -1,
+			want: "player,games,wins,deaths,win_rate,clutch\n" +
+				"alice,4,3,1,75.0,2\n" +
+				"bob,4,1,3,25.0,0\n" +
+				"carol,3,1,2,33.3,0\n",
+			wantPlayers: 3,
+		},
+		{
+			name:        "no stats",
+			chatID:      -2,
+			want:        "player,games,wins,deaths,win_rate,clutch\n",
+			wantPlayers: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, players := s.leaderboardCSV(tt.chatID)
+			if string(data) != tt.want || players != tt.wantPlayers {
+				t.Errorf("leaderboardCSV() = %d player(s):\n%s\nwant %d:\n%s", players, data, tt.wantPlayers, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeaderboardCSVEscapesFormulas(t *testing.T) {
+	s := &statsStore{Chats: map[int64]map[string]*PlayerStats{
+		-1: {
+			`=HYPERLINK("http://evil","x")`: {GamesPlayed: 5, Wins: 4},
+			"+1":                            {GamesPlayed: 5, Wins: 3},
+			"-2":                            {GamesPlayed: 5, Wins: 2},
+			"@cmd":                          {GamesPlayed: 5, Wins: 1},
+			"bob=":                          {GamesPlayed: 5},
+		},
+	}}
+	want := "player,games,wins,deaths,win_rate,clutch\n" +
+		`"'=HYPERLINK(""http://evil"",""x"")",5,4,0,80.0,0` + "\n" +
+		"'+1,5,3,0,60.0,0\n" +
+		"'-2,5,2,0,40.0,0\n" +
+		"'@cmd,5,1,0,20.0,0\n" +
+		"bob=,5,0,0,0.0,0\n"
+	if data, _ := s.leaderboardCSV(-1); string(data) != want {
+		t.Errorf("leaderboardCSV() =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestClutchBonus(t *testing.T) {
+	tests := []struct {
+		odds      float64
+		threshold int
+		want      int
+	}{
+		{50, 50, 5},
+		{66.7, 50, 6},
+		{100, 50, 10},
+		{49.9, 50, 0},
+		{16.7, 50, 0},
+		{20, 20, 2},
+		{80, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := clutchBonus(tt.odds, tt.threshold); got != tt.want {
+			t.Errorf("clutchBonus(%.1f, %d) = %d, want %d", tt.odds, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+func TestRecordClutch(t *testing.T) {
+	testChat(t)
+	game := runningGame(t, defaultConfig(), lastInChamber())
+	game.Bots["bot"] = BotPlayer{}
+	practice := runningGame(t, defaultConfig(), lastInChamber())
+	practice.Practice = true
+
+	tests := []struct {
+		name   string
+		game   *Game
+		player string
+		odds   float64
+		want   int
+	}{
+		{"even odds", game, "alice", 50, 5},
+		{"low odds", game, "bob", 16.7, 0},
+		{"bot", game, "bot", 50, 0},
+		{"practice", practice, "carol", 50, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stats.recordClutch(-1, tt.game, tt.player, tt.odds); got != tt.want {
+				t.Errorf("recordClutch() = %d, want %d", got, tt.want)
+			}
+			if got := stats.get(-1, tt.player).Clutch; got != tt.want {
+				t.Errorf("clutch points recorded = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsFollowTheTelegramID(t *testing.T) {
+	testChat(t)
+	stats.Chats[-1] = map[string]*PlayerStats{"dave": {GamesPlayed: 2, Wins: 2}}
+	game := lobbyGame(t, defaultConfig(), lastInChamber())
+	game.Join("4", "@dave")
+	game.Start()
+
+	// Stats from before players were keyed by their ID move over to it
+	stats.recordGame(-1, game, "alice")
+	if got := stats.get(-1, "4"); got.GamesPlayed != 3 || got.Wins != 3 || got.Name != "@dave" {
+		t.Errorf("dave's stats = %+v, want 3 games won as @dave", got)
+	}
+	if _, ok := stats.Chats[-1]["dave"]; ok {
+		t.Error("the stats kept under the username are still there")
+	}
+
+	// A new username is remembered by the next game, and /stats finds it
+	game.Names["4"] = "@dave_2"
+	stats.recordGame(-1, game, "alice")
+	if got := stats.player(-1, "Dave_2"); got != "4" {
+		t.Errorf("player(Dave_2) = %q, want 4", got)
+	}
+	if got := stats.player(-1, "dave"); got != "dave" {
+		t.Errorf("player(dave) after the rename = %q, want the mention back", got)
+	}
+	if got := stats.get(-1, "4").GamesPlayed; got != 4 {
+		t.Errorf("dave's games after the rename = %d, want 4", got)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		outcomes []bool
+		want     string
+	}{
+		{nil, ""},
+		{[]bool{true}, "▇"},
+		{[]bool{false}, "▁"},
+		{[]bool{true, false, false, true}, "▇▁▁▇"},
+	}
+	for _, tt := range tests {
+		if got := sparkline(tt.outcomes); got != tt.want {
+			t.Errorf("sparkline(%v) = %q, want %q", tt.outcomes, got, tt.want)
+		}
+	}
+}
+
+func TestRecentKeepsTheLatestGames(t *testing.T) {
+	var ps PlayerStats
+	for i := 0; i < recentGames+3; i++ {
+		ps.addRecent(i%3 == 0)
+	}
+	if len(ps.Recent) != recentGames {
+		t.Fatalf("kept %d outcomes, want %d", len(ps.Recent), recentGames)
+	}
+	// Games 3 to 12 are kept, every third one a win
+	if got, want := sparkline(ps.Recent), "▇▁▁▇▁▁▇▁▁▇"; got != want {
+		t.Errorf("trend = %q, want %q", got, want)
+	}
+
+	got := formatPlayerStats("@alice", PlayerStats{GamesPlayed: 3, Wins: 1, Recent: []bool{false, true, false}})
+	if !strings.Contains(got, "Last 3 game(s): ▁▇▁") {
+		t.Errorf("stats lack the trend:\n%s", got)
+	}
+}
+
+func TestBadges(t *testing.T) {
+	s := &statsStore{Chats: map[int64]map[string]*PlayerStats{
+		-1: {
+			"alice": {GamesPlayed: 9, Wins: 6},
+			"bob":   {GamesPlayed: 9, Wins: 2},
+			"carol": {GamesPlayed: 9, Wins: 2},
+			"dave":  {GamesPlayed: 9, Wins: 1},
+		},
+		-2: {
+			"alice": {GamesPlayed: 1, Wins: 1},
+			"bob":   {GamesPlayed: 1, Deaths: 1},
+		},
+	}}
+
+	tests := []struct {
+		name   string
+		chatID int64
+		want   map[string]string
+	}{
+		{"top three", -1, map[string]string{"alice": "🥇", "bob": "🥈", "carol": "🥉"}},
+		{"only winners", -2, map[string]string{"alice": "🥇"}},
+		{"no stats", -3, map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.badges(tt.chatID); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("badges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tucnak/telebot"
+)
+
+// SuddenRound is one round of a sudden death game, in which every player
+// pulled once at the same time
+type SuddenRound struct {
+	Hit []string // Players whose pull was fatal
+}
+
+// Sudden starts a game from the lobby and resolves it in one go. Every round
+// each player pulls a random chamber of a freshly spun cylinder, and the game
+// ends after the first round in which anyone is hit; everyone hit then loses.
+func (g *Game) Sudden() ([]SuddenRound, error) {
+	if err := g.Start(); err != nil {
+		return nil, err
+	}
+
+	var rounds []SuddenRound
+	for g.IsActive {
+		var round SuddenRound
+		for _, player := range g.Players {
+			g.reload()
+			odds := 100 * float64(g.Bullets) / float64(g.Chambers)
+			if g.Cylinder[g.rng.Intn(g.Chambers)] {
+				round.Hit = append(round.Hit, player)
+				g.record(EventDeath, player, odds)
+				continue
+			}
+			g.record(EventPull, player, odds)
+		}
+		g.FullRound = true
+		if len(round.Hit) > 0 {
+			g.IsActive = false
+		}
+		rounds = append(rounds, round)
+	}
+	return rounds, nil
+}
+
+// playSudden resolves the chat's game in the lobby instantly and announces the
+// losers. The caller has the chat locked.
+func playSudden(s Sender, chat *telebot.Chat, game *Game) {
+	s = senderFor(s, game)
+	rounds, err := game.Sudden()
+	if err != nil {
+		s.Send(chat, errorMessage(err, game))
+		return
+	}
+	stopJoinWindow(game)
+	rememberSettings(chat.ID, game)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "⚡ Sudden death! Everyone pulls at once with %d bullet(s) in %d chambers...", game.Bullets, game.Chambers)
+	for i, round := range rounds[:len(rounds)-1] {
+		fmt.Fprintf(&b, "\nRound %d: *click* x%d, everyone survives!", i+1, len(game.Players)-len(round.Hit))
+	}
+	losers := rounds[len(rounds)-1].Hit
+	verb := "are"
+	if len(losers) == 1 {
+		verb = "is"
+	}
+	fmt.Fprintf(&b, "\nRound %d: 💥 BANG! %s %s dead! Game Over!", len(rounds), game.nameList(losers), verb)
+	b.WriteString(winnerLine(game, losers...))
+	announceEnd(s, chat, b.String())
+
+	sendRecap(s, chat, game)
+	endGame(s, chat, game, losers...)
+}
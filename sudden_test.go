@@ -0,0 +1,66 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSuddenScripted(t *testing.T) {
+	// Each pull loads the bullet into chamber 1 and then fires the chamber given
+	tests := []struct {
+		name       string
+		fired      []int
+		wantRounds int
+		wantHit    []string
+	}{
+		{"first round", []int{1, 1, 0}, 1, []string{"carol"}},
+		{"two hit", []int{0, 3, 0}, 1, []string{"alice", "carol"}},
+		{"second round", []int{1, 2, 3, 4, 0, 5}, 2, []string{"bob"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var values []int
+			for _, chamber := range tt.fired {
+				values = append(values, 0, chamber)
+			}
+			g := lobbyGame(t, defaultConfig(), &scriptedRandomizer{})
+			g.rng = &scriptedRandomizer{values: values}
+
+			rounds, err := g.Sudden()
+			if err != nil {
+				t.Fatalf("Sudden() = %v", err)
+			}
+			if len(rounds) != tt.wantRounds {
+				t.Fatalf("played %d rounds, want %d", len(rounds), tt.wantRounds)
+			}
+			if hit := rounds[len(rounds)-1].Hit; !slices.Equal(hit, tt.wantHit) {
+				t.Errorf("hit = %v, want %v", hit, tt.wantHit)
+			}
+			if g.IsActive {
+				t.Error("the game is still active")
+			}
+		})
+	}
+}
+
+func TestSuddenSeededIsDeterministic(t *testing.T) {
+	play := func() []SuddenRound {
+		g := lobbyGame(t, defaultConfig(), newSeededRandomizer(2024))
+		rounds, err := g.Sudden()
+		if err != nil {
+			t.Fatalf("Sudden() = %v", err)
+		}
+		return rounds
+	}
+	if first, again := play(), play(); !reflect.DeepEqual(first, again) {
+		t.Errorf("the same seed played %v and %v", first, again)
+	}
+}
+
+func TestSuddenNeedsLobby(t *testing.T) {
+	g := runningGame(t, defaultConfig(), lastInChamber())
+	if _, err := g.Sudden(); err == nil {
+		t.Error("Sudden() on a running game succeeded")
+	}
+}
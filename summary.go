@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// gameSummary recaps a finished game from its event log: who won, how many
+// pulls it took, who died and when, the closest call and how long it lasted.
+// Times of day are shown in loc.
+func gameSummary(g *Game, loc *time.Location) string {
+	var start, end time.Time
+	var deaths []string // One line per death, as a sudden death game can have several
+	dead := make(map[string]bool)
+	pulls, cheats := 0, 0
+	closest := Event{}
+	for _, e := range g.Events {
+		switch e.Kind {
+		case EventStart:
+			start = e.Time
+		case EventPull:
+			pulls++
+			if e.Odds > closest.Odds {
+				closest = e
+			}
+		case EventCheat:
+			cheats++
+		case EventKick:
+			deaths = append(deaths, fmt.Sprintf("Kicked: %s after %d pull(s)\n", g.name(e.Player), pulls))
+			end = e.Time
+		case EventDeath:
+			pulls++
+			dead[e.Player] = true
+			deaths = append(deaths, fmt.Sprintf("Died: %s on pull #%d\n", g.name(e.Player), pulls))
+			end = e.Time
+		}
+	}
+
+	var fallen []string
+	for player := range dead {
+		fallen = append(fallen, player)
+	}
+	survivors := g.survivors(fallen...)
+
+	var b strings.Builder
+	b.WriteString("📋 Game summary\n")
+	if len(survivors) == 1 {
+		fmt.Fprintf(&b, "Winner: %s\n", g.name(survivors[0]))
+	} else if len(survivors) > 1 {
+		fmt.Fprintf(&b, "Survivors: %s\n", g.nameList(survivors))
+	}
+	fmt.Fprintf(&b, "Total pulls: %d\n", pulls)
+	for _, death := range deaths {
+		b.WriteString(death)
+	}
+	if closest.Player != "" {
+		fmt.Fprintf(&b, "Closest call: %s survived a %.1f%% pull\n", g.name(closest.Player), closest.Odds)
+	}
+	if cheats > 0 {
+		fmt.Fprintf(&b, "🎰 The house cheated: the bullets were secretly moved %d time(s)!\n", cheats)
+	}
+	fmt.Fprintf(&b, "Started: %s\n", formatTime(start, loc))
+	fmt.Fprintf(&b, "Duration: %s", formatDuration(end.Sub(start)))
+	return b.String()
+}
+
+// formatTime renders a timestamp in loc, naming the zone
+func formatTime(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("Mon 2 Jan 15:04 MST")
+}
+
+// formatDuration renders a game length rounded to whole seconds
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGameSummary(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.NoShuffle = true
+	// The bullet is in the third chamber
+	g := newGame("alice", "Alice", cfg, &scriptedRandomizer{values: []int{2}})
+	g.Join("bob", "Bob")
+	if err := g.Start(); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	for _, step := range []string{"alice", "bob", "alice"} {
+		if _, err := g.Pull(step); err != nil {
+			t.Fatalf("Pull(%s) = %v", step, err)
+		}
+		if g.IsActive {
+			g.Pass(step)
+		}
+	}
+	if g.IsActive {
+		t.Fatal("the third pull didn't end the game")
+	}
+
+	start := time.Date(2026, time.March, 3, 20, 15, 0, 0, time.UTC)
+	for i := range g.Events {
+		g.Events[i].Time = start.Add(time.Duration(i) * 20 * time.Second)
+	}
+	g.Events[len(g.Events)-1].Time = start.Add(83 * time.Second)
+
+	want := "📋 Game summary\n" +
+		"Winner: Bob\n" +
+		"Total pulls: 3\n" +
+		"Died: Alice on pull #3\n" +
+		"Closest call: Bob survived a 20.0% pull\n" +
+		"Started: Tue 3 Mar 20:15 UTC\n" +
+		"Duration: 1m23s"
+	if got := gameSummary(g, time.UTC); got != want {
+		t.Errorf("gameSummary() =\n%s\nwant\n%s", got, want)
+	}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no timezone data: %v", err)
+	}
+	if got := formatTime(start, berlin); got != "Tue 3 Mar 21:15 CET" {
+		t.Errorf("start in Berlin = %q", got)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{1499 * time.Millisecond, "1s"},
+		{83 * time.Second, "1m23s"},
+		{-time.Second, "0s"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+const (
+	// graveWindow is how long after dying a player can still /taunt
+	graveWindow = 5 * time.Minute
+	// tauntCooldown is the minimum time between two taunts from the grave
+	tauntCooldown  = 30 * time.Second
+	maxTauntLength = 200
+)
+
+// grave is a player eliminated in a chat's most recent game
+type grave struct {
+	Player    string
+	Name      string
+	Died      time.Time
+	LastTaunt time.Time
+
+	game *Game // The game they died in
+}
+
+// bury remembers the players who just died so they can taunt the survivors.
+// Players of game who were buried earlier, like those knocked out of an
+// elimination game, keep their grave, while graves from older games go.
+func bury(chatID int64, game *Game, dead ...string) {
+	r := roomOf(chatID)
+	now := time.Now()
+	var buried []*grave
+	for _, g := range r.graves {
+		if g.game == game {
+			buried = append(buried, g)
+		}
+	}
+	for _, player := range dead {
+		if slices.ContainsFunc(buried, func(g *grave) bool { return g.Player == player }) {
+			continue
+		}
+		buried = append(buried, &grave{Player: player, Name: game.name(player), Died: now, game: game})
+	}
+	r.graves = buried
+}
+
+// findGrave returns the chat's recent grave of player, if they just died
+func findGrave(chatID int64, player string, now time.Time) (*grave, bool) {
+	for _, g := range roomOf(chatID).graves {
+		if g.Player == player && now.Sub(g.Died) <= graveWindow {
+			return g, true
+		}
+	}
+	return nil, false
+}
+
+// taunt relays a message from one of the chat's recently eliminated players. It
+// doesn't touch any game. The caller has the chat locked.
+func taunt(s Sender, chat *telebot.Chat, player, text string) {
+	now := time.Now()
+	g, ok := findGrave(chat.ID, player, now)
+	if !ok {
+		s.Send(chat, "Only players who just died can taunt from the grave!")
+		return
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		s.Send(chat, "Usage: /taunt <message>")
+		return
+	}
+	if wait := tauntCooldown - now.Sub(g.LastTaunt); wait > 0 {
+		s.Send(chat, fmt.Sprintf("The dead must rest! You can taunt again in %d second(s).", int(wait.Seconds())+1))
+		return
+	}
+	if len([]rune(text)) > maxTauntLength {
+		text = string([]rune(text)[:maxTauntLength]) + "…"
+	}
+
+	g.LastTaunt = now
+	s.Send(chat, fmt.Sprintf("👻 %s from the grave: %s", g.Name, text))
+}
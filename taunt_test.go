@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTauntFromTheGrave(t *testing.T) {
+	chat := testChat(t)
+	cfg := defaultConfig()
+	cfg.Elimination = true
+	// The first cylinder kills alice at once, the next one is loaded last
+	game := playChat(t, chat, cfg, &scriptedRandomizer{values: []int{0, defaultChambers - 1}})
+	s := &recordingSender{}
+
+	playPull(s, chat, game, "alice")
+	if !game.IsActive || game.HasPlayer("alice") {
+		t.Fatalf("alice wasn't eliminated from a running game: active %t, players %v", game.IsActive, game.Players)
+	}
+
+	tests := []struct {
+		name   string
+		player string
+		text   string
+		want   string
+	}{
+		{"eliminated player", "alice", "you're next", "👻 Alice from the grave: you're next"},
+		{"too soon", "alice", "again", "The dead must rest!"},
+		{"living player", "bob", "boo", "Only players who just died can taunt"},
+		{"spectator", "dave", "boo", "Only players who just died can taunt"},
+	}
+	for _, tt := range tests {
+		s.sent = nil
+		taunt(s, chat, tt.player, tt.text)
+		if len(s.sent) != 1 || !strings.HasPrefix(s.sent[0], tt.want) {
+			t.Errorf("%s: taunt sent %q, want %q", tt.name, s.sent, tt.want)
+		}
+	}
+
+	if game.CurrentPlayer() != "bob" || game.PullCount != 0 {
+		t.Errorf("taunts changed the game: %s's turn after %d pulls", game.CurrentPlayer(), game.PullCount)
+	}
+}
+
+func TestBuryKeepsEarlierDeathsOfTheGame(t *testing.T) {
+	chat := testChat(t)
+	first := runningGame(t, defaultConfig(), lastInChamber())
+	second := runningGame(t, defaultConfig(), lastInChamber())
+
+	bury(chat.ID, first, "alice")
+	bury(chat.ID, first, "bob", "alice")
+	if graves := roomOf(chat.ID).graves; len(graves) != 2 {
+		t.Fatalf("%d graves after burying alice twice and bob, want 2", len(graves))
+	}
+
+	bury(chat.ID, second, "carol")
+	graves := roomOf(chat.ID).graves
+	if len(graves) != 1 || graves[0].Player != "carol" {
+		t.Errorf("graves after the next game = %v, want only carol's", graves)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+const (
+	minTurnTimeout = 10   // seconds
+	maxTurnTimeout = 3600 // seconds
+)
+
+// defaultTurnTimeout is the turn timer of new games in seconds, changed
+// with TURN_TIMEOUT_SECONDS
+var defaultTurnTimeout = 120
+
+// loadTurnTimeout reads TURN_TIMEOUT_SECONDS, where 0 turns the timer off by
+// default. It must run before the chat configs are loaded, which start from
+// the defaults.
+func loadTurnTimeout() {
+	value := os.Getenv("TURN_TIMEOUT_SECONDS")
+	if value == "" {
+		return
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || (n != 0 && (n < minTurnTimeout || n > maxTurnTimeout)) {
+		log.Printf("Invalid TURN_TIMEOUT_SECONDS %q, using %d", value, defaultTurnTimeout)
+		return
+	}
+	defaultTurnTimeout = n
+}
+
+// nudgeCooldown is how often the current player of a game can be nudged
+const nudgeCooldown = time.Minute
+
+// ExtendTurn gives the current player one more full turn timer. It can only
+// be done once per turn.
+func (g *Game) ExtendTurn() error {
+	if err := g.requirePhase(PhaseRunning); err != nil {
+		return err
+	}
+	if g.TurnTimeout == 0 {
+		return ErrNoTurnTimer
+	}
+	if g.turnExtended {
+		return ErrAlreadyExtended
+	}
+
+	g.turnExtended = true
+	return nil
+}
+
+// scheduleTurnTimer (re)starts the timer of the current turn, replacing any
+// timer still running for the game. When it runs out the player passes if
+// they have pulled, skips if they can, and otherwise has to pull.
+func scheduleTurnTimer(s Sender, chat *telebot.Chat, game *Game) {
+	if game.turnTimer != nil {
+		game.turnTimer.Stop()
+		game.turnTimer = nil
+	}
+	if game.TurnTimeout == 0 || !game.IsActive || game.Phase != PhaseRunning {
+		return
+	}
+	player := game.CurrentPlayer()
+	if _, isBot := game.Bots[player]; isBot {
+		return
+	}
+
+	generation := game.Generation
+	var timer *time.Timer
+	timer = time.AfterFunc(time.Duration(game.TurnTimeout)*time.Second, func() {
+		defer lockChat(chat.ID).unlock()
+
+		// A timer that was replaced may still fire if it was already running
+		game, err := liveGame(chat.ID, generation)
+		if err != nil || game.turnTimer != timer || game.CurrentPlayer() != player {
+			return
+		}
+		game.turnTimer = nil
+
+		s.Send(chat, fmt.Sprintf("⏰ %s ran out of time!", game.name(player)))
+		switch {
+		case game.HasPulledOnTurn:
+			playPass(s, chat, game, player)
+		case game.Skips[player] > 0:
+			playSkip(s, chat, game, player)
+		default:
+			playPull(s, chat, game, player)
+		}
+	})
+	game.turnTimer = timer
+}
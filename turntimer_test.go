@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExtendTurnOncePerTurn(t *testing.T) {
+	chat := testChat(t)
+	game := playChat(t, chat, defaultConfig(), lastInChamber())
+	s := &recordingSender{}
+
+	scheduleTurnTimer(s, chat, game)
+	timer := game.turnTimer
+
+	if err := game.ExtendTurn(); err != nil {
+		t.Fatalf("first ExtendTurn() = %v", err)
+	}
+	scheduleTurnTimer(s, chat, game)
+	if game.turnTimer == nil || game.turnTimer == timer {
+		t.Error("extending the turn didn't restart its timer")
+	}
+	if err := game.ExtendTurn(); !errors.Is(err, ErrAlreadyExtended) {
+		t.Errorf("second ExtendTurn() = %v, want %v", err, ErrAlreadyExtended)
+	}
+
+	// The next player gets an extension of their own
+	playSkip(s, chat, game, "alice")
+	if err := game.ExtendTurn(); err != nil {
+		t.Errorf("ExtendTurn() on the next turn = %v", err)
+	}
+}
+
+func TestExtendTurnNeedsTimer(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.TurnTimeout = 0
+	g := runningGame(t, cfg, lastInChamber())
+	if err := g.ExtendTurn(); !errors.Is(err, ErrNoTurnTimer) {
+		t.Errorf("ExtendTurn() without a timer = %v, want %v", err, ErrNoTurnTimer)
+	}
+
+	g = lobbyGame(t, defaultConfig(), lastInChamber())
+	if err := g.ExtendTurn(); !errors.Is(err, ErrGameNotStarted) {
+		t.Errorf("ExtendTurn() in the lobby = %v, want %v", err, ErrGameNotStarted)
+	}
+}
+
+func TestTurnTimerRunsOut(t *testing.T) {
+	chat := testChat(t)
+	cfg := defaultConfig()
+	cfg.TurnTimeout = 1
+	game := playChat(t, chat, cfg, lastInChamber())
+	s := &recordingSender{}
+
+	r := lockChat(chat.ID)
+	scheduleTurnTimer(s, chat, game)
+	r.unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.sentWith("ran out of time")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the turn timer never ran out")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	r = lockChat(chat.ID)
+	defer r.unlock()
+	// alice had skips left, so the timer used one for her
+	if game.CurrentPlayer() != "bob" || game.Skips["alice"] != defaultSkips-1 {
+		t.Errorf("after the timeout it's %s's turn and alice has %d skips", game.CurrentPlayer(), game.Skips["alice"])
+	}
+}
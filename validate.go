@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+// Inconsistencies validate finds in a game. They can only come from a bug,
+// but a game in such a state would leave its players stuck.
+var (
+	errNoPlayers     = errors.New("running game has no players")
+	errBadCurrentPos = errors.New("current position is outside the roster")
+	errBadCylinder   = errors.New("cylinder doesn't match the chamber count")
+	errNoBulletLeft  = errors.New("no bullet left in the cylinder")
+	errMissingSkips  = errors.New("player has no skip count")
+)
+
+// maxRepairAttempts bounds repair in case fixing one problem uncovers another
+const maxRepairAttempts = 3
+
+// validate checks the invariants a game in play must hold
+func validate(g *Game) error {
+	if len(g.Cylinder) != g.Chambers || g.PullCount < 0 || g.PullCount > g.Chambers {
+		return fmt.Errorf("%w: %d chambers, cylinder of %d, %d pulls", errBadCylinder, g.Chambers, len(g.Cylinder), g.PullCount)
+	}
+	if g.Phase != PhaseRunning {
+		return nil
+	}
+	if len(g.Players) == 0 {
+		return errNoPlayers
+	}
+	if g.CurrentPos < 0 || g.CurrentPos >= len(g.Players) {
+		return fmt.Errorf("%w: %d of %d", errBadCurrentPos, g.CurrentPos, len(g.Players))
+	}
+	for _, player := range g.Players {
+		if _, ok := g.Skips[player]; !ok {
+			return fmt.Errorf("%w: %s", errMissingSkips, player)
+		}
+	}
+	if !g.SpinEach && g.remainingBullets() == 0 {
+		return errNoBulletLeft
+	}
+	return nil
+}
+
+// repair logs an inconsistent game and fixes what it can: the turn is moved
+// back into the roster, a broken cylinder is reloaded and missing skips are
+// filled in. A game that can't be played any more is ended. It reports
+// whether the game can go on.
+func repair(chatID int64, g *Game) bool {
+	for i := 0; i < maxRepairAttempts; i++ {
+		err := validate(g)
+		if err == nil {
+			return true
+		}
+		log.Printf("Inconsistent game in chat %d: %v", chatID, err)
+
+		switch {
+		case errors.Is(err, errBadCurrentPos):
+			g.CurrentPos = ((g.CurrentPos % len(g.Players)) + len(g.Players)) % len(g.Players)
+		case errors.Is(err, errBadCylinder) && g.Chambers >= minChambers && g.Chambers <= maxChambers:
+			g.reload()
+		case errors.Is(err, errNoBulletLeft):
+			g.reload()
+		case errors.Is(err, errMissingSkips):
+			for _, player := range g.Players {
+				if _, ok := g.Skips[player]; !ok {
+					g.Skips[player] = 0
+				}
+			}
+		default:
+			// Nothing sensible to repair, so end the game rather than leave it stuck
+			g.IsActive = false
+			roomOf(chatID).game = nil
+			log.Printf("Ended the broken game in chat %d", chatID)
+			return false
+		}
+	}
+
+	g.IsActive = false
+	roomOf(chatID).game = nil
+	log.Printf("Ended the game in chat %d, it couldn't be repaired", chatID)
+	return false
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateAndRepair(t *testing.T) {
+	tests := []struct {
+		name      string
+		breakGame func(g *Game)
+		wantErr   error
+		repaired  bool
+		check     func(g *Game) bool
+	}{
+		{"consistent", func(g *Game) {}, nil, true, nil},
+		{"turn past the roster", func(g *Game) { g.CurrentPos = 4 }, errBadCurrentPos, true,
+			func(g *Game) bool { return g.CurrentPlayer() == "bob" }},
+		{"negative turn", func(g *Game) { g.CurrentPos = -1 }, errBadCurrentPos, true,
+			func(g *Game) bool { return g.CurrentPlayer() == "carol" }},
+		{"short cylinder", func(g *Game) { g.Cylinder = g.Cylinder[:2] }, errBadCylinder, true,
+			func(g *Game) bool { return len(g.Cylinder) == g.Chambers && g.PullCount == 0 }},
+		{"emptied cylinder", func(g *Game) { g.Cylinder = make([]bool, g.Chambers) }, errNoBulletLeft, true,
+			func(g *Game) bool { return g.remainingBullets() == g.Bullets }},
+		{"missing skips", func(g *Game) { delete(g.Skips, "bob") }, errMissingSkips, true,
+			func(g *Game) bool { s, ok := g.Skips["bob"]; return ok && s == 0 }},
+		{"no players", func(g *Game) { g.Players = nil }, errNoPlayers, false, nil},
+		{"impossible chamber count", func(g *Game) { g.Chambers = 0 }, errBadCylinder, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := testChat(t)
+			game := playChat(t, chat, defaultConfig(), lastInChamber())
+			tt.breakGame(game)
+
+			if err := validate(game); !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("validate() = %v, want %v", err, tt.wantErr)
+			}
+			if got := repair(chat.ID, game); got != tt.repaired {
+				t.Fatalf("repair() = %t, want %t", got, tt.repaired)
+			}
+			if !tt.repaired {
+				if game.IsActive || roomOf(chat.ID).game != nil {
+					t.Error("an unrepairable game was left in the chat")
+				}
+				return
+			}
+			if err := validate(game); err != nil {
+				t.Errorf("validate() after repair = %v", err)
+			}
+			if tt.check != nil && !tt.check(game) {
+				t.Errorf("repair left turn %d, cylinder %v and skips %v", game.CurrentPos, game.Cylinder, game.Skips)
+			}
+		})
+	}
+}
+
+func TestActiveGameRepairs(t *testing.T) {
+	chat := testChat(t)
+	game := playChat(t, chat, defaultConfig(), lastInChamber())
+
+	game.CurrentPos = len(game.Players)
+	if got, err := activeGame(chat.ID); err != nil || got != game {
+		t.Errorf("activeGame() of a repairable game = %v, %v", got, err)
+	}
+
+	game.Players = nil
+	if _, err := activeGame(chat.ID); !errors.Is(err, ErrNoActiveGame) {
+		t.Errorf("activeGame() of a broken game = %v, want %v", err, ErrNoActiveGame)
+	}
+}
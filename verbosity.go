@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/tucnak/telebot"
+)
+
+// Verbosity settings of a chat, changed with /config verbosity
+const (
+	verbosityMinimal = "minimal" // Only turn changes, deaths and the end of the game
+	verbosityNormal  = "normal"
+	verbosityVerbose = "verbose" // Also the odds the next player faces when the turn changes
+)
+
+var verbosityRanks = map[string]int{
+	verbosityMinimal: 0,
+	verbosityNormal:  1,
+	verbosityVerbose: 2,
+}
+
+// chatty reports whether the chat wants messages of the given verbosity
+func chatty(chatID int64, level string) bool {
+	return verbosityRanks[chats.get(chatID).Verbosity] >= verbosityRanks[level]
+}
+
+// chatter sends a message the game can be played without, if the chat's
+// verbosity includes its level
+func chatter(s Sender, chat *telebot.Chat, level, text string) {
+	if chatty(chat.ID, level) {
+		s.Send(chat, text)
+	}
+}
+
+// nextUp announces whose turn it is, with the odds they face in verbose chats
+func nextUp(chat *telebot.Chat, game *Game) string {
+	msg := fmt.Sprintf("Next up: %s", game.name(game.CurrentPlayer()))
+	if chatty(chat.ID, verbosityVerbose) {
+		msg += fmt.Sprintf("\nChance of the first pull being fatal: %.1f%%", game.NextOdds())
+	}
+	return msg
+}
@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+func TestVerbosity(t *testing.T) {
+	tests := []struct {
+		level       string
+		wantJoin    bool
+		wantDetails bool
+		wantOdds    bool
+	}{
+		{verbosityMinimal, false, false, false},
+		{verbosityNormal, true, true, false},
+		{verbosityVerbose, true, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			chat := testChat(t)
+			if err := chats.set(chat.ID, "verbosity", tt.level); err != nil {
+				t.Fatalf("set(verbosity, %s) = %v", tt.level, err)
+			}
+			s := &recordingSender{}
+
+			lobby := lobbyGame(t, defaultConfig(), lastInChamber())
+			lobby.Join("dave", "Dave")
+			announceJoin(s, chat, lobby, "dave", "")
+			if got := len(s.sentWith("Dave joined the game")) == 1; got != tt.wantJoin {
+				t.Errorf("join confirmation sent = %t, want %t", got, tt.wantJoin)
+			}
+
+			game := playChat(t, chat, defaultConfig(), &scriptedRandomizer{values: []int{2}})
+			playPull(s, chat, game, "alice")
+			if len(s.sentWith("*click* Alice survives!")) != 1 {
+				t.Fatalf("survival wasn't announced: %q", s.sent)
+			}
+			if got := len(s.sentWith("Chambers left: 5")) == 1; got != tt.wantDetails {
+				t.Errorf("survival details sent = %t, want %t", got, tt.wantDetails)
+			}
+			playPass(s, chat, game, "alice")
+			if len(s.sentWith("Next up: bob")) != 1 {
+				t.Errorf("turn change wasn't announced: %q", s.sent)
+			}
+			if got := len(s.sentWith("Chance of the first pull being fatal")) == 1; got != tt.wantOdds {
+				t.Errorf("next player's odds sent = %t, want %t", got, tt.wantOdds)
+			}
+
+			// The bullet is in the third chamber
+			playPull(s, chat, game, "bob")
+			playPull(s, chat, game, "bob")
+			if len(s.sentWith("💥 BANG! bob is dead! Game Over!")) != 1 {
+				t.Errorf("death wasn't announced: %q", s.sent)
+			}
+		})
+	}
+}